@@ -0,0 +1,66 @@
+package thirdpartyhosting
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+)
+
+// attachDetachKeys is the key sequence that detaches from an attached container without stopping it
+const attachDetachKeys = "ctrl-p,ctrl-q"
+
+// detachedMessage is printed by docker attach when the detach keys are read
+const detachedMessage = "read escape sequence"
+
+// Attach connects in and out to the stdio of a service's running container until the
+// container exits, ctx is cancelled or in sends the detach keys (ctrl-p, ctrl-q).
+// Detaching returns nil and leaves the container running. With a nil in, stdin is not attached.
+func (p *DockerComposeProvider) Attach(ctx context.Context, serviceName string, in io.Reader, out io.Writer) error {
+	containerID, err := p.ResolveContainerID(ctx, serviceName)
+	if err != nil {
+		return err
+	}
+
+	args := []string{"attach", "--detach-keys", attachDetachKeys}
+	if in == nil {
+		args = append(args, "--no-stdin")
+	}
+	args = append(args, containerID)
+
+	detector := &detachDetector{out: out}
+	err = p.commands().Stream(ctx, in, detector, "docker", args...)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	if err != nil && !detector.detached() {
+		return fmt.Errorf("failed to attach to service %s: %w", serviceName, err)
+	}
+
+	return nil
+}
+
+// detachDetector forwards output while watching for the message docker attach
+// prints when it detaches, which it reports with a non-zero exit status
+type detachDetector struct {
+	out  io.Writer
+	tail []byte // the last bytes written, enough to hold detachedMessage
+}
+
+// Write forwards b to out and keeps the end of the stream for detached
+func (d *detachDetector) Write(b []byte) (int, error) {
+	d.tail = append(d.tail, b...)
+	if extra := len(d.tail) - len(detachedMessage) - 1; extra > 0 {
+		d.tail = d.tail[extra:]
+	}
+
+	if d.out == nil {
+		return len(b), nil
+	}
+	return d.out.Write(b)
+}
+
+// detached reports whether the output ended with the detach message
+func (d *detachDetector) detached() bool {
+	return bytes.Contains(d.tail, []byte(detachedMessage))
+}
@@ -0,0 +1,81 @@
+package thirdpartyhosting
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// attachHandler answers ps for app and the attach command with output and err
+func attachHandler(output string, err error) func(ctx context.Context, command string) ([]byte, error) {
+	return func(ctx context.Context, command string) ([]byte, error) {
+		if strings.HasSuffix(command, "ps -q app") {
+			return []byte("abc123\n"), nil
+		}
+		if strings.HasPrefix(command, "docker attach") {
+			return []byte(output), err
+		}
+		return nil, nil
+	}
+}
+
+func TestAttach(t *testing.T) {
+	provider, runner := newTestProvider(t, testConfig(), attachHandler("psql> \n", nil))
+	var out bytes.Buffer
+
+	err := provider.Attach(context.Background(), "app", strings.NewReader("SELECT 1;\n"), &out)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "psql> \n", out.String())
+	assert.Equal(t, []string{"SELECT 1;\n"}, runner.inputs)
+	assert.Equal(t, []string{"docker attach --detach-keys ctrl-p,ctrl-q abc123"}, runner.CallsContaining("docker attach"))
+}
+
+func TestAttachWithoutStdin(t *testing.T) {
+	provider, runner := newTestProvider(t, testConfig(), attachHandler("", nil))
+
+	assert.NoError(t, provider.Attach(context.Background(), "app", nil, nil))
+	assert.Len(t, runner.CallsContaining("--no-stdin abc123"), 1)
+}
+
+func TestAttachDetachKeys(t *testing.T) {
+	provider, _ := newTestProvider(t, testConfig(), attachHandler("read escape sequence\n", errors.New("exit status 1")))
+
+	err := provider.Attach(context.Background(), "app", strings.NewReader("\x10\x11"), nil)
+
+	assert.NoError(t, err)
+}
+
+func TestAttachContainerFails(t *testing.T) {
+	provider, _ := newTestProvider(t, testConfig(), attachHandler("", errors.New("exit status 137")))
+
+	err := provider.Attach(context.Background(), "app", nil, nil)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to attach to service app")
+}
+
+func TestAttachContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	provider, _ := newTestProvider(t, testConfig(), func(c context.Context, command string) ([]byte, error) {
+		if strings.HasPrefix(command, "docker attach") {
+			cancel()
+			return nil, errors.New("signal: killed")
+		}
+		return attachHandler("", nil)(c, command)
+	})
+
+	err := provider.Attach(ctx, "app", nil, nil)
+
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestAttachUnknownService(t *testing.T) {
+	provider, _ := newTestProvider(t, testConfig(), nil)
+
+	assert.Error(t, provider.Attach(context.Background(), "missing", nil, nil))
+}
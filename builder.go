@@ -0,0 +1,104 @@
+package thirdpartyhosting
+
+// ComposeBuilder constructs a ComposeConfig fluently, e.g.
+//
+//	config, err := NewComposeBuilder("fider").
+//		AddService("db").Image("postgres:13").
+//		AddService("app").Image("getfider/fider:stable").Port(3000, 3000).Env("BASE_URL", "http://localhost:3000").DependsOn("db").
+//		Build()
+type ComposeBuilder struct {
+	config ComposeConfig
+}
+
+// ServiceBuilder sets the fields of one service added through ComposeBuilder.AddService
+type ServiceBuilder struct {
+	parent  *ComposeBuilder
+	service ServiceConfig
+	name    string
+}
+
+// NewComposeBuilder starts a config for the named compose project
+func NewComposeBuilder(project string) *ComposeBuilder {
+	return &ComposeBuilder{
+		config: ComposeConfig{
+			ProjectName: project,
+			Services:    make(map[string]ServiceConfig),
+		},
+	}
+}
+
+// Network sets the network the services are attached to
+func (b *ComposeBuilder) Network(name string) *ComposeBuilder {
+	b.config.Network = name
+	return b
+}
+
+// AddService starts a new service; adding a name again replaces the earlier service
+func (b *ComposeBuilder) AddService(name string) *ServiceBuilder {
+	service := &ServiceBuilder{parent: b, name: name}
+	return service.commit()
+}
+
+// Build validates the config with ValidateConfig and returns it
+func (b *ComposeBuilder) Build() (ComposeConfig, error) {
+	config := b.config.Clone()
+	if err := ValidateConfig(config); err != nil {
+		return ComposeConfig{}, err
+	}
+	return config, nil
+}
+
+// Image sets the image reference, e.g. "postgres:13"
+func (s *ServiceBuilder) Image(ref string) *ServiceBuilder {
+	s.service.ImageName, s.service.ImageTag = splitImageRef(ref)
+	return s.commit()
+}
+
+// Port publishes a container port on the host
+func (s *ServiceBuilder) Port(hostPort, containerPort int) *ServiceBuilder {
+	s.service.ExposedPorts = append(s.service.ExposedPorts, PortMapping{HostPort: hostPort, ContainerPort: containerPort})
+	return s.commit()
+}
+
+// Env sets an environment variable
+func (s *ServiceBuilder) Env(key, value string) *ServiceBuilder {
+	if s.service.Environment == nil {
+		s.service.Environment = make(map[string]string)
+	}
+	s.service.Environment[key] = value
+	return s.commit()
+}
+
+// Volume mounts a host path or named volume into the container
+func (s *ServiceBuilder) Volume(hostPath, containerPath string) *ServiceBuilder {
+	s.service.Volumes = append(s.service.Volumes, VolumeMapping{HostPath: hostPath, ContainerPath: containerPath})
+	return s.commit()
+}
+
+// DependsOn adds services this one starts after
+func (s *ServiceBuilder) DependsOn(services ...string) *ServiceBuilder {
+	s.service.DependsOn = append(s.service.DependsOn, services...)
+	return s.commit()
+}
+
+// Restart sets the restart policy, e.g. "always"
+func (s *ServiceBuilder) Restart(policy string) *ServiceBuilder {
+	s.service.RestartPolicy = policy
+	return s.commit()
+}
+
+// AddService finishes this service and starts the next one
+func (s *ServiceBuilder) AddService(name string) *ServiceBuilder {
+	return s.parent.AddService(name)
+}
+
+// Build finishes this service and builds the whole config
+func (s *ServiceBuilder) Build() (ComposeConfig, error) {
+	return s.parent.Build()
+}
+
+// commit stores the service in the parent config so each step is visible to Build
+func (s *ServiceBuilder) commit() *ServiceBuilder {
+	s.parent.config.Services[s.name] = s.service
+	return s
+}
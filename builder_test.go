@@ -0,0 +1,58 @@
+package thirdpartyhosting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComposeBuilder(t *testing.T) {
+	config, err := NewComposeBuilder("fider").
+		Network("fider-net").
+		AddService("db").Image("postgres:13").Volume("pg_data", "/var/lib/postgresql/data").
+		AddService("app").Image("getfider/fider:stable").Port(3000, 3000).
+		Env("BASE_URL", "http://localhost:3000").DependsOn("db").Restart("always").
+		Build()
+
+	require.NoError(t, err)
+	assert.Equal(t, ComposeConfig{
+		ProjectName: "fider",
+		Network:     "fider-net",
+		Services: map[string]ServiceConfig{
+			"db": {
+				ImageName: "postgres",
+				ImageTag:  "13",
+				Volumes:   []VolumeMapping{{HostPath: "pg_data", ContainerPath: "/var/lib/postgresql/data"}},
+			},
+			"app": {
+				ImageName:     "getfider/fider",
+				ImageTag:      "stable",
+				ExposedPorts:  []PortMapping{{HostPort: 3000, ContainerPort: 3000}},
+				Environment:   map[string]string{"BASE_URL": "http://localhost:3000"},
+				DependsOn:     []string{"db"},
+				RestartPolicy: "always",
+			},
+		},
+	}, config)
+}
+
+func TestComposeBuilderValidationFailure(t *testing.T) {
+	_, err := NewComposeBuilder("broken").
+		AddService("app").Port(80, 80).
+		Build()
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "service app")
+}
+
+func TestComposeBuilderResultIsIndependent(t *testing.T) {
+	builder := NewComposeBuilder("app")
+	service := builder.AddService("app").Image("app").Env("MODE", "prod")
+
+	config, err := builder.Build()
+	require.NoError(t, err)
+
+	service.Env("MODE", "dev")
+	assert.Equal(t, "prod", config.Services["app"].Environment["MODE"])
+}
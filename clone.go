@@ -0,0 +1,145 @@
+package thirdpartyhosting
+
+// Clone returns a deep copy of the config, so the copy shares no maps, slices or
+// pointers with the original
+func (c ComposeConfig) Clone() ComposeConfig {
+	clone := c
+	clone.ActiveProfiles = cloneStrings(c.ActiveProfiles)
+	clone.NetworkLabels = cloneStringMap(c.NetworkLabels)
+	clone.VolumeLabels = cloneStringMap(c.VolumeLabels)
+
+	if c.Services != nil {
+		clone.Services = make(map[string]ServiceConfig, len(c.Services))
+		for name, service := range c.Services {
+			clone.Services[name] = service.clone()
+		}
+	}
+
+	if c.Configs != nil {
+		clone.Configs = make(map[string]ConfigFile, len(c.Configs))
+		for name, config := range c.Configs {
+			clone.Configs[name] = config
+		}
+	}
+
+	return clone
+}
+
+// clone returns a deep copy of the service config
+func (s ServiceConfig) clone() ServiceConfig {
+	clone := s
+	if s.ExposedPorts != nil {
+		clone.ExposedPorts = append([]PortMapping{}, s.ExposedPorts...)
+	}
+	clone.Environment = cloneStringMap(s.Environment)
+	if s.Volumes != nil {
+		clone.Volumes = append([]VolumeMapping{}, s.Volumes...)
+	}
+	clone.VolumesFrom = cloneStrings(s.VolumesFrom)
+	clone.EnvPassthrough = cloneStrings(s.EnvPassthrough)
+	clone.Entrypoint = cloneStrings(s.Entrypoint)
+	clone.Command = cloneStrings(s.Command)
+	clone.DependsOn = cloneStrings(s.DependsOn)
+	clone.Links = cloneStrings(s.Links)
+	clone.ExternalLinks = cloneStrings(s.ExternalLinks)
+	clone.Profiles = cloneStrings(s.Profiles)
+	if s.Configs != nil {
+		clone.Configs = append([]ConfigMount{}, s.Configs...)
+	}
+	clone.DNSOpt = cloneStrings(s.DNSOpt)
+	clone.GroupAdd = cloneStrings(s.GroupAdd)
+	clone.SecurityOpt = cloneStrings(s.SecurityOpt)
+
+	if s.Dependencies != nil {
+		clone.Dependencies = make(map[string]Dependency, len(s.Dependencies))
+		for name, dependency := range s.Dependencies {
+			clone.Dependencies[name] = dependency
+		}
+	}
+
+	clone.Resources.MemSwappiness = cloneInt(s.Resources.MemSwappiness)
+	clone.Resources.OOMScoreAdj = cloneInt(s.Resources.OOMScoreAdj)
+
+	if s.BlkioConfig != nil {
+		blkio := *s.BlkioConfig
+		blkio.DeviceReadBps = cloneDeviceRates(s.BlkioConfig.DeviceReadBps)
+		blkio.DeviceWriteBps = cloneDeviceRates(s.BlkioConfig.DeviceWriteBps)
+		blkio.DeviceReadIOps = cloneDeviceRates(s.BlkioConfig.DeviceReadIOps)
+		blkio.DeviceWriteIOps = cloneDeviceRates(s.BlkioConfig.DeviceWriteIOps)
+		clone.BlkioConfig = &blkio
+	}
+
+	if s.Deploy != nil {
+		deploy := *s.Deploy
+		if deploy.RestartPolicy != nil {
+			policy := *deploy.RestartPolicy
+			deploy.RestartPolicy = &policy
+		}
+		deploy.Replicas = cloneInt(deploy.Replicas)
+		clone.Deploy = &deploy
+	}
+
+	if s.HealthCheck != nil {
+		healthCheck := *s.HealthCheck
+		healthCheck.Test = cloneStrings(s.HealthCheck.Test)
+		clone.HealthCheck = &healthCheck
+	}
+
+	if s.Build != nil {
+		build := *s.Build
+		build.Args = cloneStringMap(s.Build.Args)
+		build.CacheFrom = cloneStrings(s.Build.CacheFrom)
+		clone.Build = &build
+	}
+
+	if s.Develop != nil {
+		develop := *s.Develop
+		if s.Develop.Watch != nil {
+			develop.Watch = make([]WatchRule, len(s.Develop.Watch))
+			for i, rule := range s.Develop.Watch {
+				rule.Ignore = cloneStrings(rule.Ignore)
+				develop.Watch[i] = rule
+			}
+		}
+		clone.Develop = &develop
+	}
+
+	return clone
+}
+
+// cloneStrings copies a slice, preserving the difference between nil and empty
+func cloneStrings(values []string) []string {
+	if values == nil {
+		return nil
+	}
+	return append([]string{}, values...)
+}
+
+// cloneStringMap copies a map, preserving nil
+func cloneStringMap(values map[string]string) map[string]string {
+	if values == nil {
+		return nil
+	}
+	clone := make(map[string]string, len(values))
+	for key, value := range values {
+		clone[key] = value
+	}
+	return clone
+}
+
+// cloneDeviceRates copies blkio device limits, preserving nil
+func cloneDeviceRates(rates []BlkioDeviceRate) []BlkioDeviceRate {
+	if rates == nil {
+		return nil
+	}
+	return append([]BlkioDeviceRate{}, rates...)
+}
+
+// cloneInt copies an optional int
+func cloneInt(value *int) *int {
+	if value == nil {
+		return nil
+	}
+	v := *value
+	return &v
+}
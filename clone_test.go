@@ -0,0 +1,129 @@
+package thirdpartyhosting
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fullConfig returns a config that populates every map, slice and pointer Clone copies
+func fullConfig() ComposeConfig {
+	swappiness, score, replicas := 10, -500, 2
+	return ComposeConfig{
+		ProjectName:    "clone",
+		ActiveProfiles: []string{"debug"},
+		Configs:        map[string]ConfigFile{"nginx": {Content: "worker_processes 1;"}},
+		NetworkLabels:  map[string]string{"team": "web"},
+		VolumeLabels:   map[string]string{"team": "web"},
+		Services: map[string]ServiceConfig{
+			"app": {
+				ImageName:      "app",
+				ExposedPorts:   []PortMapping{{HostPort: 8080, ContainerPort: 80}},
+				Environment:    map[string]string{"MODE": "prod"},
+				Volumes:        []VolumeMapping{{HostPath: "data", ContainerPath: "/data"}},
+				VolumesFrom:    []string{"db:ro"},
+				EnvPassthrough: []string{"SECRET"},
+				Entrypoint:     []string{},
+				Command:        []string{"serve"},
+				DependsOn:      []string{"db"},
+				Dependencies:   map[string]Dependency{"db": {Condition: "service_healthy"}},
+				Links:          []string{"db"},
+				ExternalLinks:  []string{"shared:cache"},
+				Profiles:       []string{"debug"},
+				Deploy: &DeployConfig{RestartPolicy: &DeployRestartPolicy{
+					Condition: "on-failure", Delay: time.Second,
+				}, Replicas: &replicas},
+				Resources:   ResourceLimits{MemSwappiness: &swappiness, OOMScoreAdj: &score},
+				BlkioConfig: &BlkioConfig{Weight: 300, DeviceWriteBps: []BlkioDeviceRate{{Path: "/dev/sda", Rate: "10mb"}}},
+				HealthCheck: &HealthCheck{Test: []string{"CMD", "true"}},
+				Build:       &BuildConfig{Context: ".", Args: map[string]string{"V": "1"}, CacheFrom: []string{"app:cache"}},
+				Develop:     &DevelopConfig{Watch: []WatchRule{{Path: "src", Action: "sync", Target: "/src", Ignore: []string{"tmp/"}}}},
+				Configs:     []ConfigMount{{Source: "nginx"}},
+				DNSOpt:      []string{"ndots:2"},
+				GroupAdd:    []string{"docker"},
+				SecurityOpt: []string{SecurityOptNoNewPrivileges},
+			},
+			"db": {ImageName: "postgres"},
+		},
+	}
+}
+
+func TestComposeConfigCloneIsEqual(t *testing.T) {
+	original := fullConfig()
+
+	assert.Equal(t, original, original.Clone())
+	assert.Equal(t, ComposeConfig{}, ComposeConfig{}.Clone())
+}
+
+func TestComposeConfigCloneSharesNothing(t *testing.T) {
+	original := fullConfig()
+	clone := original.Clone()
+
+	app := original.Services["app"]
+	app.ExposedPorts[0].HostPort = 1
+	app.Environment["MODE"] = "dev"
+	app.Volumes[0].ReadOnly = true
+	app.Command[0] = "migrate"
+	app.Dependencies["db"] = Dependency{}
+	*app.Resources.MemSwappiness = 99
+	app.BlkioConfig.DeviceWriteBps[0].Rate = "1mb"
+	app.Deploy.RestartPolicy.Condition = "any"
+	*app.Deploy.Replicas = 5
+	app.HealthCheck.Test[1] = "false"
+	app.Build.Args["V"] = "2"
+	app.Develop.Watch[0].Ignore[0] = "log/"
+	app.Configs[0].Target = "/etc/nginx.conf"
+	original.Services["cache"] = ServiceConfig{ImageName: "redis"}
+	original.Configs["nginx"] = ConfigFile{File: "nginx.conf"}
+	original.ActiveProfiles[0] = "prod"
+	original.NetworkLabels["team"] = "ops"
+	original.VolumeLabels["team"] = "ops"
+
+	assert.Equal(t, fullConfig(), clone)
+}
+
+func TestInitializeStoresClone(t *testing.T) {
+	config := testConfig()
+	provider, _ := newTestProvider(t, config, nil)
+
+	config.Services["app"].DependsOn[0] = "cache"
+	config.Services["cache"] = ServiceConfig{ImageName: "redis"}
+
+	assert.ElementsMatch(t, []string{"app", "db"}, provider.GetServices())
+
+	provider.mu.RLock()
+	defer provider.mu.RUnlock()
+	require.Contains(t, provider.config.Services, "app")
+	assert.Equal(t, []string{"db"}, provider.config.Services["app"].DependsOn)
+}
+
+// Run with -race: the caller keeps mutating its config while Status reads the provider's copy
+func TestInitializeConfigNotSharedWithCaller(t *testing.T) {
+	config := testConfig()
+	provider, _ := newTestProvider(t, config, healthHandler(map[string]string{"app": "running", "db": "running"}))
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			config.Services[fmt.Sprintf("extra-%d", i)] = ServiceConfig{ImageName: "busybox"}
+			app := config.Services["app"]
+			app.Environment = map[string]string{"N": fmt.Sprint(i)}
+			config.Services["app"] = app
+			delete(config.Services, "db")
+		}
+	}()
+
+	for i := 0; i < 20; i++ {
+		statuses, err := provider.Status(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"app": "running", "db": "running"}, statuses)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,109 @@
+package thirdpartyhosting
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// defaultGracePeriod is how long a cancelled command has to exit after SIGTERM before it
+// is killed, matching docker stop's default
+const defaultGracePeriod = 10 * time.Second
+
+// commandRunner executes the docker and docker-compose commands issued by the provider
+type commandRunner interface {
+	// Run executes the command and returns its combined stdout and stderr
+	Run(ctx context.Context, name string, args ...string) ([]byte, error)
+
+	// Stream executes the command, feeding it stdin and writing stdout and stderr to output as they arrive
+	Stream(ctx context.Context, stdin io.Reader, output io.Writer, name string, args ...string) error
+}
+
+// execRunner runs commands on the host using os/exec. When the context is cancelled the
+// command is sent SIGTERM so docker-compose can stop what it started, and is killed if it
+// is still running after gracePeriod (defaultGracePeriod when zero). Commands see env,
+// or this process's environment when env is nil.
+type execRunner struct {
+	gracePeriod time.Duration
+	env         []string
+}
+
+// Run executes the command and returns its combined stdout and stderr
+func (r execRunner) Run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return r.command(ctx, name, args...).CombinedOutput()
+}
+
+// Stream executes the command, feeding it stdin and writing stdout and stderr to output as they arrive
+func (r execRunner) Stream(ctx context.Context, stdin io.Reader, output io.Writer, name string, args ...string) error {
+	cmd := r.command(ctx, name, args...)
+	cmd.Stdin = stdin
+	cmd.Stdout = output
+	cmd.Stderr = output
+	return cmd.Run()
+}
+
+// command builds a command that is terminated gracefully when ctx is cancelled
+func (r execRunner) command(ctx context.Context, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Env = r.env
+	cmd.Cancel = func() error {
+		return cmd.Process.Signal(syscall.SIGTERM)
+	}
+	cmd.WaitDelay = r.gracePeriod
+	if cmd.WaitDelay <= 0 {
+		cmd.WaitDelay = defaultGracePeriod
+	}
+	return cmd
+}
+
+// commandEnv builds the environment docker commands run with from InheritEnv and ExtraEnv.
+// It returns nil, meaning this process's environment unchanged, when there is nothing to
+// add or remove.
+func (p *DockerComposeProvider) commandEnv() []string {
+	if p.InheritEnv && len(p.ExtraEnv) == 0 {
+		return nil
+	}
+
+	var env []string
+	if p.InheritEnv {
+		for _, entry := range os.Environ() {
+			key, _, _ := strings.Cut(entry, "=")
+			if _, overridden := p.ExtraEnv[key]; !overridden {
+				env = append(env, entry)
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(p.ExtraEnv))
+	for key := range p.ExtraEnv {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		env = append(env, key+"="+p.ExtraEnv[key])
+	}
+
+	if env == nil {
+		env = []string{} // an empty, not inherited, environment
+	}
+	return env
+}
+
+// lookupCommandEnv looks key up in the environment docker commands run with, see commandEnv
+func (p *DockerComposeProvider) lookupCommandEnv(key string) (string, bool) {
+	env := p.commandEnv()
+	if env == nil {
+		return os.LookupEnv(key)
+	}
+	for i := len(env) - 1; i >= 0; i-- {
+		if k, value, _ := strings.Cut(env[i], "="); k == key {
+			return value, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,95 @@
+package thirdpartyhosting
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// requireShell skips tests that need a POSIX shell to stand in for a long-running docker-compose
+func requireShell(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+}
+
+func TestExecRunnerSendsSIGTERMOnCancel(t *testing.T) {
+	requireShell(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	var output bytes.Buffer
+	start := time.Now()
+	script := `trap 'echo cleaning up; exit 0' TERM; echo started; while :; do sleep 0.01; done`
+	err := execRunner{gracePeriod: 5 * time.Second}.Stream(ctx, nil, &output, "sh", "-c", script)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, "started\ncleaning up\n", output.String())
+	assert.Less(t, time.Since(start), 5*time.Second, "the process exited on SIGTERM without waiting for SIGKILL")
+}
+
+func TestExecRunnerKillsAfterGracePeriod(t *testing.T) {
+	requireShell(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	script := `trap '' TERM; while :; do sleep 0.01; done`
+	_, err := execRunner{gracePeriod: 200 * time.Millisecond}.Run(ctx, "sh", "-c", script)
+	elapsed := time.Since(start)
+
+	assert.Error(t, err)
+	assert.GreaterOrEqual(t, elapsed, 250*time.Millisecond, "SIGKILL must wait for the grace period")
+	assert.Less(t, elapsed, 5*time.Second)
+}
+
+func TestCommandEnvInheritsByDefault(t *testing.T) {
+	provider := NewDockerComposeProvider()
+
+	assert.True(t, provider.InheritEnv)
+	assert.Nil(t, provider.commandEnv(), "nil leaves the process environment to exec")
+}
+
+func TestCommandEnvInheritWithExtra(t *testing.T) {
+	t.Setenv("DOCKER_HOST", "unix:///var/run/docker.sock")
+	t.Setenv("KEEP_ME", "yes")
+	provider := NewDockerComposeProvider()
+	provider.ExtraEnv = map[string]string{"DOCKER_HOST": "unix:///run/user/1000/docker.sock", "DOCKER_CONTEXT": "rootless"}
+
+	env := provider.commandEnv()
+
+	assert.Contains(t, env, "KEEP_ME=yes")
+	assert.Contains(t, env, "DOCKER_HOST=unix:///run/user/1000/docker.sock")
+	assert.Contains(t, env, "DOCKER_CONTEXT=rootless")
+	assert.NotContains(t, env, "DOCKER_HOST=unix:///var/run/docker.sock")
+}
+
+func TestCommandEnvWithoutInherit(t *testing.T) {
+	t.Setenv("SECRET_TOKEN", "hunter2")
+	provider := NewDockerComposeProvider()
+	provider.InheritEnv = false
+
+	assert.Equal(t, []string{}, provider.commandEnv())
+
+	provider.ExtraEnv = map[string]string{"PATH": "/usr/bin:/bin", "DOCKER_CONTEXT": "ci"}
+	assert.Equal(t, []string{"DOCKER_CONTEXT=ci", "PATH=/usr/bin:/bin"}, provider.commandEnv())
+}
+
+func TestCommandsApplyEnv(t *testing.T) {
+	requireShell(t)
+	t.Setenv("SECRET_TOKEN", "hunter2")
+	provider := NewDockerComposeProvider()
+	provider.InheritEnv = false
+	provider.ExtraEnv = map[string]string{"DOCKER_CONTEXT": "ci"}
+
+	output, err := provider.commands().Run(context.Background(), "sh", "-c", `echo "$DOCKER_CONTEXT ${SECRET_TOKEN:-unset}"`)
+
+	require.NoError(t, err)
+	assert.Equal(t, "ci unset\n", string(output))
+}
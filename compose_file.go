@@ -0,0 +1,941 @@
+package thirdpartyhosting
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultComposeVersion is the compose file format version written to generated files
+const defaultComposeVersion = "3.4"
+
+// ComposeSpecVersion selects the versionless compose specification, which omits the version key
+const ComposeSpecVersion = "spec"
+
+// composeSchemaVersion returns the compose file version config renders, or ComposeSpecVersion
+func composeSchemaVersion(config ComposeConfig) string {
+	if config.ComposeVersion == "" {
+		return defaultComposeVersion
+	}
+	return config.ComposeVersion
+}
+
+// composeBinaryVersion matches the release in docker-compose version output, e.g.
+// "docker-compose version 1.29.2, build 5becea4c" or "Docker Compose version v2.24.5"
+var composeBinaryVersion = regexp.MustCompile(`version v?(\d+)\.\d+`)
+
+// detectComposeVersion returns the compose file version suited to the docker-compose
+// binary that printed output: the versionless spec for Compose v2 and later, which
+// warns about the version key, and the 3.4 default for v1. It returns "" for output it
+// does not recognize.
+func detectComposeVersion(output []byte) string {
+	firstLine, _, _ := strings.Cut(string(output), "\n")
+	match := composeBinaryVersion.FindStringSubmatch(firstLine)
+	if match == nil {
+		return ""
+	}
+	if major, err := strconv.Atoi(match[1]); err == nil && major >= 2 {
+		return ComposeSpecVersion
+	}
+	return defaultComposeVersion
+}
+
+// withDetectedComposeVersion returns config with ComposeVersion defaulted to the version
+// Validate detected from the docker-compose binary; an explicit ComposeVersion is kept
+func (p *DockerComposeProvider) withDetectedComposeVersion(config ComposeConfig) ComposeConfig {
+	if config.ComposeVersion == "" {
+		p.mu.RLock()
+		config.ComposeVersion = p.fileVersion
+		p.mu.RUnlock()
+	}
+	return config
+}
+
+// ComposeFile is the structured form of a generated docker-compose.yml
+type ComposeFile struct {
+	Version  string // empty for the versionless compose spec
+	Services map[string]*ComposeService
+	Networks map[string]ComposeNetwork
+	Volumes  map[string]ComposeVolume // named volumes referenced by services
+	Configs  map[string]ComposeConfigFile
+}
+
+// ComposeService is a single entry under the services key
+type ComposeService struct {
+	Image           string
+	Build           *ComposeBuild
+	Develop         []ComposeWatchRule // develop.watch rules
+	PullPolicy      string
+	Platform        string
+	Entrypoint      []string // nil omits the key, empty renders entrypoint: []
+	Command         []string // nil omits the key, empty renders command: []
+	EntrypointShell string   // shell form, rendered as a scalar instead of Entrypoint
+	CommandShell    string   // shell form, rendered as a scalar instead of Command
+	StdinOpen       bool
+	TTY             bool
+	Restart         string
+	Ports           []string // short syntax, e.g., "8080:80/tcp"
+	Volumes         []ComposeServiceVolume
+	VolumesFrom     []string
+	EnvFile         []string
+	Environment     map[string]string
+	EnvPassthrough  []string                     // rendered as bare "KEY" entries after Environment
+	DependsOn       []string                     // short list form
+	Dependencies    map[string]ComposeDependency // long form; replaces DependsOn when set
+	Links           []string
+	ExternalLinks   []string
+	Profiles        []string
+	Deploy          *ComposeDeploy
+	MemSwapLimit    string
+	MemSwappiness   *int
+	OOMKillDisable  bool
+	OOMScoreAdj     *int
+	CPUSet          string
+	Blkio           *ComposeBlkioConfig
+	CgroupParent    string
+	Cgroup          string
+	Runtime         string
+	Isolation       string
+	MacAddress      string
+	DNSOpt          []string
+	ReadOnly        bool
+	GroupAdd        []string
+	SecurityOpt     []string
+	Configs         []ComposeConfigMount
+	HealthCheck     *ComposeHealthCheck
+}
+
+// ComposeDependency is a long-form depends_on entry
+type ComposeDependency struct {
+	Condition string
+	Restart   bool
+}
+
+// ComposeWatchRule is an entry under a service's develop.watch key
+type ComposeWatchRule struct {
+	Path   string
+	Action string
+	Target string
+	Ignore []string
+}
+
+// ComposeConfigMount is a service configs entry, written in short form when Target is empty
+type ComposeConfigMount struct {
+	Source string
+	Target string
+}
+
+// ComposeConfigFile is an entry under the top-level configs key
+type ComposeConfigFile struct {
+	File    string
+	Content string
+}
+
+// ComposeBlkioConfig is a service's blkio_config section
+type ComposeBlkioConfig struct {
+	Weight          int
+	DeviceReadBps   []ComposeDeviceRate
+	DeviceWriteBps  []ComposeDeviceRate
+	DeviceReadIOps  []ComposeDeviceRate
+	DeviceWriteIOps []ComposeDeviceRate
+}
+
+// ComposeDeviceRate is a per-device blkio_config throttle entry
+type ComposeDeviceRate struct {
+	Path string
+	Rate string
+}
+
+// ComposeBuild is a service's build section
+type ComposeBuild struct {
+	Context    string
+	Dockerfile string
+	Args       map[string]string
+	Target     string
+	CacheFrom  []string
+}
+
+// ComposeServiceVolume is a service volume entry. Short holds the "source:target[:ro]"
+// form; when it is empty the entry is written in long form from the other fields.
+type ComposeServiceVolume struct {
+	Short       string
+	Type        string
+	Source      string
+	Target      string
+	ReadOnly    bool
+	Consistency string
+	Propagation string
+	NoCopy      bool
+}
+
+// ComposeDeploy is a service's deploy section
+type ComposeDeploy struct {
+	Replicas      *int
+	Resources     *ComposeResources
+	RestartPolicy *ComposeRestartPolicy
+}
+
+// ComposeRestartPolicy is the deploy restart_policy section
+type ComposeRestartPolicy struct {
+	Condition   string
+	Delay       string
+	MaxAttempts int
+	Window      string
+}
+
+// ComposeResources holds the deploy resource limits
+type ComposeResources struct {
+	Limits ComposeLimits
+}
+
+// ComposeLimits holds the deploy resource limit values
+type ComposeLimits struct {
+	Memory string
+	CPUs   string
+}
+
+// ComposeHealthCheck is a service's healthcheck section
+type ComposeHealthCheck struct {
+	Disable     bool // render only disable: true, ignoring the other fields
+	Test        []string
+	Interval    string
+	Timeout     string
+	Retries     int
+	StartPeriod string
+}
+
+// ComposeNetwork is an entry under the top-level networks key
+type ComposeNetwork struct {
+	Driver string
+	Labels map[string]string
+}
+
+// ComposeVolume is an entry under the top-level volumes key
+type ComposeVolume struct {
+	Driver string
+	Labels map[string]string
+}
+
+// RenderComposeStruct builds the structured compose file for config. It applies the
+// same defaults and path resolution as the generated docker-compose.yml.
+func RenderComposeStruct(config ComposeConfig) (*ComposeFile, error) {
+	config, err := resolveExtends(config)
+	if err != nil {
+		return nil, err
+	}
+
+	// The generated file lives in a temporary directory, so a relative env file is resolved
+	// against BaseDir here rather than left for docker-compose to look up next to it
+	envFile := resolveRelativePath(config.BaseDir, config.EnvFile)
+
+	// Read the env file up front when its values are inlined
+	var fileEnv map[string]string
+	if envFile != "" && config.InlineEnvFile {
+		var err error
+		if fileEnv, err = parseEnvFile(envFile); err != nil {
+			return nil, err
+		}
+	}
+
+	version := composeSchemaVersion(config)
+	if version == ComposeSpecVersion {
+		version = ""
+	}
+
+	file := &ComposeFile{
+		Version:  version,
+		Services: make(map[string]*ComposeService, len(config.Services)),
+		Networks: make(map[string]ComposeNetwork),
+		Volumes:  make(map[string]ComposeVolume),
+		Configs:  make(map[string]ComposeConfigFile, len(config.Configs)),
+	}
+
+	for name, configFile := range config.Configs {
+		file.Configs[name] = ComposeConfigFile{
+			File:    resolveRelativePath(config.BaseDir, configFile.File),
+			Content: configFile.Content,
+		}
+	}
+
+	for serviceName, serviceConfig := range config.Services {
+		service := &ComposeService{
+			PullPolicy:      serviceConfig.PullPolicy,
+			Platform:        servicePlatform(config, serviceConfig),
+			Entrypoint:      serviceConfig.Entrypoint,
+			Command:         serviceConfig.Command,
+			EntrypointShell: serviceConfig.EntrypointShell,
+			CommandShell:    serviceConfig.CommandShell,
+			StdinOpen:       serviceConfig.StdinOpen,
+			TTY:             serviceConfig.TTY,
+			Restart:         serviceConfig.RestartPolicy,
+			Environment:     mergeEnv(fileEnv, serviceConfig.Environment),
+			VolumesFrom:     serviceConfig.VolumesFrom,
+			EnvPassthrough:  passthroughKeys(serviceConfig),
+			DependsOn:       serviceConfig.DependsOn,
+			Links:           serviceConfig.Links,
+			ExternalLinks:   serviceConfig.ExternalLinks,
+			Profiles:        serviceConfig.Profiles,
+			MemSwapLimit:    serviceConfig.Resources.MemSwapLimit,
+			MemSwappiness:   serviceConfig.Resources.MemSwappiness,
+			OOMKillDisable:  serviceConfig.Resources.OOMKillDisable,
+			OOMScoreAdj:     serviceConfig.Resources.OOMScoreAdj,
+			CPUSet:          serviceConfig.Resources.CPUSet,
+			CgroupParent:    serviceConfig.CgroupParent,
+			Cgroup:          serviceConfig.Cgroup,
+			Runtime:         serviceConfig.Runtime,
+			Isolation:       serviceConfig.Isolation,
+			MacAddress:      serviceConfig.MacAddress,
+			DNSOpt:          serviceConfig.DNSOpt,
+			ReadOnly:        serviceConfig.ReadOnlyRootFS,
+			GroupAdd:        serviceConfig.GroupAdd,
+		}
+
+		if serviceConfig.ImageName != "" {
+			service.Image = imageRef(serviceConfig)
+		}
+
+		if build := serviceConfig.Build; build != nil {
+			service.Build = &ComposeBuild{
+				Context:    resolveBuildContext(config.BaseDir, build.Context),
+				Dockerfile: build.Dockerfile,
+				Args:       build.Args,
+				Target:     build.Target,
+				CacheFrom:  build.CacheFrom,
+			}
+		}
+
+		if blkio := serviceConfig.BlkioConfig; blkio != nil {
+			service.Blkio = &ComposeBlkioConfig{
+				Weight:          blkio.Weight,
+				DeviceReadBps:   composeDeviceRates(blkio.DeviceReadBps),
+				DeviceWriteBps:  composeDeviceRates(blkio.DeviceWriteBps),
+				DeviceReadIOps:  composeDeviceRates(blkio.DeviceReadIOps),
+				DeviceWriteIOps: composeDeviceRates(blkio.DeviceWriteIOps),
+			}
+		}
+
+		for _, opt := range serviceConfig.SecurityOpt {
+			service.SecurityOpt = append(service.SecurityOpt, resolveSecurityOpt(config.BaseDir, opt))
+		}
+
+		if serviceConfig.Develop != nil {
+			for _, rule := range serviceConfig.Develop.Watch {
+				service.Develop = append(service.Develop, ComposeWatchRule{
+					Path:   resolveRelativePath(config.BaseDir, rule.Path),
+					Action: rule.Action,
+					Target: rule.Target,
+					Ignore: rule.Ignore,
+				})
+			}
+		}
+
+		for _, mount := range serviceConfig.Configs {
+			service.Configs = append(service.Configs, ComposeConfigMount{Source: mount.Source, Target: mount.Target})
+		}
+
+		for _, port := range serviceConfig.ExposedPorts {
+			service.Ports = append(service.Ports, portSpec(port))
+		}
+
+		for _, volume := range serviceConfig.Volumes {
+			volumeType := volumeMappingType(volume)
+			if volumeType == "bind" {
+				volume.HostPath = resolveRelativePath(config.BaseDir, volume.HostPath)
+			}
+			if volumeType == "volume" && volume.HostPath != "" {
+				file.Volumes[volume.HostPath] = ComposeVolume{Labels: config.VolumeLabels}
+			}
+			service.Volumes = append(service.Volumes, composeServiceVolume(volume, config.UseLongVolumeSyntax))
+		}
+
+		// Reference the env file unless its values are inlined
+		if envFile != "" && !config.InlineEnvFile {
+			service.EnvFile = []string{envFile}
+		}
+
+		if deps := composeDependencies(serviceConfig); deps != nil {
+			service.DependsOn = nil
+			service.Dependencies = deps
+		}
+
+		service.Deploy = composeDeploy(serviceConfig)
+
+		if serviceConfig.DisableHealthCheck {
+			service.HealthCheck = &ComposeHealthCheck{Disable: true}
+		} else if hc := serviceConfig.HealthCheck; hc != nil {
+			service.HealthCheck = &ComposeHealthCheck{
+				Test:        healthCheckTest(*hc),
+				Interval:    formatDuration(hc.Interval),
+				Timeout:     formatDuration(hc.Timeout),
+				Retries:     hc.Retries,
+				StartPeriod: formatDuration(hc.StartPeriod),
+			}
+		}
+
+		file.Services[serviceName] = service
+	}
+
+	if config.Network != "" {
+		file.Networks[config.Network] = ComposeNetwork{Driver: "bridge", Labels: config.NetworkLabels}
+	}
+
+	return file, nil
+}
+
+// servicePlatform returns the platform a service is pulled and built for: its own, or the config default
+func servicePlatform(config ComposeConfig, service ServiceConfig) string {
+	if service.Platform != "" {
+		return service.Platform
+	}
+	return config.Platform
+}
+
+// healthCheckTest returns the healthcheck test list with its form made explicit:
+// "CMD-SHELL" for Shell, and "CMD" for a Test list without a form prefix
+func healthCheckTest(hc HealthCheck) []string {
+	if hc.Shell != "" {
+		return []string{"CMD-SHELL", hc.Shell}
+	}
+	if len(hc.Test) == 0 {
+		return nil
+	}
+	switch hc.Test[0] {
+	case "CMD", "CMD-SHELL", "NONE":
+		return hc.Test
+	}
+	return append([]string{"CMD"}, hc.Test...)
+}
+
+// composeDeploy builds the deploy section from resource limits and deploy settings, or nil if there is none
+func composeDeploy(serviceConfig ServiceConfig) *ComposeDeploy {
+	deploy := &ComposeDeploy{}
+	if serviceConfig.Resources.Memory != "" || serviceConfig.Resources.CPUShare != "" {
+		deploy.Resources = &ComposeResources{Limits: ComposeLimits{
+			Memory: serviceConfig.Resources.Memory,
+			CPUs:   serviceConfig.Resources.CPUShare,
+		}}
+	}
+	if serviceConfig.Deploy != nil && serviceConfig.Deploy.RestartPolicy != nil {
+		policy := serviceConfig.Deploy.RestartPolicy
+		deploy.RestartPolicy = &ComposeRestartPolicy{
+			Condition:   policy.Condition,
+			Delay:       formatDuration(policy.Delay),
+			MaxAttempts: policy.MaxAttempts,
+			Window:      formatDuration(policy.Window),
+		}
+	}
+
+	if serviceConfig.Deploy != nil {
+		deploy.Replicas = serviceConfig.Deploy.Replicas
+	}
+
+	if deploy.Resources == nil && deploy.RestartPolicy == nil && deploy.Replicas == nil {
+		return nil
+	}
+	return deploy
+}
+
+// composeDeviceRates converts blkio device limits
+func composeDeviceRates(rates []BlkioDeviceRate) []ComposeDeviceRate {
+	var converted []ComposeDeviceRate
+	for _, rate := range rates {
+		converted = append(converted, ComposeDeviceRate{Path: rate.Path, Rate: rate.Rate})
+	}
+	return converted
+}
+
+// composeServiceVolume converts a volume mapping, using long-form syntax when forced or
+// when the mapping needs options the short "host:container[:ro]" form can't express
+func composeServiceVolume(volume VolumeMapping, long bool) ComposeServiceVolume {
+	volumeType := volumeMappingType(volume)
+	if !long && volumeType != "tmpfs" && volume.Propagation == "" && !volume.NoCopy &&
+		volume.Consistency == "" && !isWindowsPath(volume.HostPath) {
+		short := fmt.Sprintf("%s:%s", volume.HostPath, volume.ContainerPath)
+		if volume.ReadOnly {
+			short += ":ro"
+		}
+		return ComposeServiceVolume{Short: short}
+	}
+
+	entry := ComposeServiceVolume{
+		Type:        volumeType,
+		Target:      volume.ContainerPath,
+		ReadOnly:    volume.ReadOnly,
+		Consistency: volume.Consistency,
+		Propagation: volume.Propagation,
+		NoCopy:      volume.NoCopy,
+	}
+	if volumeType != "tmpfs" {
+		entry.Source = volume.HostPath
+	}
+	return entry
+}
+
+// marshal renders the compose file as YAML formatted according to opts
+func (f *ComposeFile) marshal(opts RenderOptions) string {
+	doc := yamlMap()
+	if f.Version != "" {
+		doc.set("version", yamlQuoted(f.Version))
+	}
+
+	services := yamlMap()
+	for _, name := range sortedServiceNames(f.Services) {
+		services.set(name, f.Services[name].toYAML())
+	}
+	if opts.UseAnchors {
+		anchorRepeatedBlocks(services, opts, "environment", "deploy")
+	}
+	doc.set("services", services)
+
+	if len(f.Networks) > 0 {
+		networks := yamlMap()
+		names := make([]string, 0, len(f.Networks))
+		for name := range f.Networks {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			network := yamlMap()
+			if driver := f.Networks[name].Driver; driver != "" {
+				network.set("driver", yamlPlain(driver))
+			}
+			if labels := f.Networks[name].Labels; len(labels) > 0 {
+				network.set("labels", yamlLabels(labels))
+			}
+			networks.set(name, network)
+		}
+		doc.set("networks", networks)
+	}
+
+	if len(f.Volumes) > 0 {
+		volumes := yamlMap()
+		names := make([]string, 0, len(f.Volumes))
+		for name := range f.Volumes {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			volume := yamlMap()
+			if driver := f.Volumes[name].Driver; driver != "" {
+				volume.set("driver", yamlPlain(driver))
+			}
+			if labels := f.Volumes[name].Labels; len(labels) > 0 {
+				volume.set("labels", yamlLabels(labels))
+			}
+			volumes.set(name, volume)
+		}
+		doc.set("volumes", volumes)
+	}
+
+	if len(f.Configs) > 0 {
+		configs := yamlMap()
+		names := make([]string, 0, len(f.Configs))
+		for name := range f.Configs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			entry := yamlMap()
+			if configFile := f.Configs[name]; configFile.File != "" {
+				entry.set("file", yamlPlain(configFile.File))
+			} else {
+				entry.set("content", yamlBlock(configFile.Content))
+			}
+			configs.set(name, entry)
+		}
+		doc.set("configs", configs)
+	}
+
+	return marshalYAML(doc, opts)
+}
+
+// anchorRepeatedBlocks replaces a block under one of keys that repeats an earlier
+// service's block with an alias to it. The first occurrence is anchored as
+// "<service>-<key>"; services are visited in emission order so the anchor always
+// precedes its aliases.
+func anchorRepeatedBlocks(services *yamlNode, opts RenderOptions, keys ...string) {
+	for _, key := range keys {
+		type anchored struct {
+			node    *yamlNode
+			service string
+		}
+		first := make(map[string]anchored)
+		for i, service := range services.values {
+			for j, serviceKey := range service.keys {
+				block := service.values[j]
+				if serviceKey != key || len(block.keys)+len(block.items) == 0 {
+					continue
+				}
+
+				rendered := marshalYAML(yamlMap().set(key, block), opts)
+				original, seen := first[rendered]
+				if !seen {
+					first[rendered] = anchored{node: block, service: services.keys[i]}
+					continue
+				}
+				if original.node.anchor == "" {
+					original.node.anchor = original.service + "-" + key
+				}
+				service.values[j] = &yamlNode{kind: yamlAlias, value: original.node.anchor}
+			}
+		}
+	}
+}
+
+// toYAML builds the YAML node for a service, in the key order compose files conventionally use
+func (s *ComposeService) toYAML() *yamlNode {
+	n := yamlMap()
+	if s.Image != "" {
+		n.set("image", yamlPlain(s.Image))
+	}
+
+	if b := s.Build; b != nil {
+		build := yamlMap().set("context", yamlPlain(b.Context))
+		if b.Dockerfile != "" {
+			build.set("dockerfile", yamlPlain(b.Dockerfile))
+		}
+		if len(b.Args) > 0 {
+			build.set("args", yamlList(keyValueList(b.Args)))
+		}
+		if b.Target != "" {
+			build.set("target", yamlPlain(b.Target))
+		}
+		if len(b.CacheFrom) > 0 {
+			build.set("cache_from", yamlList(b.CacheFrom))
+		}
+		n.set("build", build)
+	}
+
+	if len(s.Develop) > 0 {
+		watch := &yamlNode{kind: yamlSequence}
+		for _, rule := range s.Develop {
+			entry := yamlMap().set("path", yamlPlain(rule.Path)).set("action", yamlPlain(rule.Action))
+			if rule.Target != "" {
+				entry.set("target", yamlPlain(rule.Target))
+			}
+			if len(rule.Ignore) > 0 {
+				entry.set("ignore", yamlList(rule.Ignore))
+			}
+			watch.append(entry)
+		}
+		n.set("develop", yamlMap().set("watch", watch))
+	}
+
+	if s.PullPolicy != "" {
+		n.set("pull_policy", yamlPlain(s.PullPolicy))
+	}
+	if s.Platform != "" {
+		n.set("platform", yamlPlain(s.Platform))
+	}
+
+	if s.EntrypointShell != "" {
+		n.set("entrypoint", yamlQuoted(s.EntrypointShell))
+	} else if s.Entrypoint != nil {
+		n.set("entrypoint", yamlFlowList(s.Entrypoint))
+	}
+	if s.CommandShell != "" {
+		n.set("command", yamlQuoted(s.CommandShell))
+	} else if s.Command != nil {
+		n.set("command", yamlFlowList(s.Command))
+	}
+	if s.StdinOpen {
+		n.set("stdin_open", yamlPlain("true"))
+	}
+	if s.TTY {
+		n.set("tty", yamlPlain("true"))
+	}
+
+	if s.Restart != "" {
+		n.set("restart", yamlPlain(s.Restart))
+	}
+
+	if len(s.Ports) > 0 {
+		ports := &yamlNode{kind: yamlSequence}
+		for _, port := range s.Ports {
+			ports.append(yamlQuoted(port))
+		}
+		n.set("ports", ports)
+	}
+
+	if len(s.Volumes) > 0 {
+		volumes := &yamlNode{kind: yamlSequence}
+		for _, volume := range s.Volumes {
+			volumes.append(volume.toYAML())
+		}
+		n.set("volumes", volumes)
+	}
+	if len(s.VolumesFrom) > 0 {
+		n.set("volumes_from", yamlList(s.VolumesFrom))
+	}
+
+	if len(s.EnvFile) > 0 {
+		n.set("env_file", yamlList(s.EnvFile))
+	}
+	if len(s.Environment) > 0 || len(s.EnvPassthrough) > 0 {
+		n.set("environment", yamlList(append(keyValueList(s.Environment), s.EnvPassthrough...)))
+	}
+	if len(s.Dependencies) > 0 {
+		dependsOn := yamlMap()
+		for _, name := range sortedDependencyNames(s.Dependencies) {
+			dep := yamlMap().set("condition", yamlPlain(s.Dependencies[name].Condition))
+			if s.Dependencies[name].Restart {
+				dep.set("restart", yamlPlain("true"))
+			}
+			dependsOn.set(name, dep)
+		}
+		n.set("depends_on", dependsOn)
+	} else if len(s.DependsOn) > 0 {
+		n.set("depends_on", yamlList(s.DependsOn))
+	}
+	if len(s.Links) > 0 {
+		n.set("links", yamlList(s.Links))
+	}
+	if len(s.ExternalLinks) > 0 {
+		n.set("external_links", yamlList(s.ExternalLinks))
+	}
+	if len(s.Profiles) > 0 {
+		n.set("profiles", yamlList(s.Profiles))
+	}
+
+	if d := s.Deploy; d != nil {
+		deploy := yamlMap()
+		if d.Replicas != nil {
+			deploy.set("replicas", yamlPlain(strconv.Itoa(*d.Replicas)))
+		}
+		if d.Resources != nil {
+			limits := yamlMap()
+			if d.Resources.Limits.Memory != "" {
+				limits.set("memory", yamlPlain(d.Resources.Limits.Memory))
+			}
+			if d.Resources.Limits.CPUs != "" {
+				limits.set("cpus", yamlPlain(d.Resources.Limits.CPUs))
+			}
+			deploy.set("resources", yamlMap().set("limits", limits))
+		}
+		if rp := d.RestartPolicy; rp != nil {
+			policy := yamlMap()
+			if rp.Condition != "" {
+				policy.set("condition", yamlPlain(rp.Condition))
+			}
+			if rp.Delay != "" {
+				policy.set("delay", yamlPlain(rp.Delay))
+			}
+			if rp.MaxAttempts > 0 {
+				policy.set("max_attempts", yamlPlain(strconv.Itoa(rp.MaxAttempts)))
+			}
+			if rp.Window != "" {
+				policy.set("window", yamlPlain(rp.Window))
+			}
+			deploy.set("restart_policy", policy)
+		}
+		n.set("deploy", deploy)
+	}
+
+	if s.MemSwapLimit != "" {
+		n.set("memswap_limit", yamlPlain(s.MemSwapLimit))
+	}
+	if s.MemSwappiness != nil {
+		n.set("mem_swappiness", yamlPlain(strconv.Itoa(*s.MemSwappiness)))
+	}
+	if s.OOMKillDisable {
+		n.set("oom_kill_disable", yamlPlain("true"))
+	}
+	if s.OOMScoreAdj != nil {
+		n.set("oom_score_adj", yamlPlain(strconv.Itoa(*s.OOMScoreAdj)))
+	}
+	if s.CPUSet != "" {
+		n.set("cpuset", yamlQuoted(s.CPUSet))
+	}
+	if b := s.Blkio; b != nil {
+		blkio := yamlMap()
+		if b.Weight > 0 {
+			blkio.set("weight", yamlPlain(strconv.Itoa(b.Weight)))
+		}
+		for _, limit := range []struct {
+			key   string
+			rates []ComposeDeviceRate
+		}{
+			{"device_read_bps", b.DeviceReadBps},
+			{"device_write_bps", b.DeviceWriteBps},
+			{"device_read_iops", b.DeviceReadIOps},
+			{"device_write_iops", b.DeviceWriteIOps},
+		} {
+			if len(limit.rates) == 0 {
+				continue
+			}
+			devices := &yamlNode{kind: yamlSequence}
+			for _, rate := range limit.rates {
+				devices.append(yamlMap().set("path", yamlPlain(rate.Path)).set("rate", yamlQuoted(rate.Rate)))
+			}
+			blkio.set(limit.key, devices)
+		}
+		n.set("blkio_config", blkio)
+	}
+	if s.CgroupParent != "" {
+		n.set("cgroup_parent", yamlPlain(s.CgroupParent))
+	}
+	if s.Cgroup != "" {
+		n.set("cgroup", yamlPlain(s.Cgroup))
+	}
+	if s.Runtime != "" {
+		n.set("runtime", yamlPlain(s.Runtime))
+	}
+	if s.Isolation != "" {
+		n.set("isolation", yamlPlain(s.Isolation))
+	}
+
+	if s.MacAddress != "" {
+		n.set("mac_address", yamlQuoted(s.MacAddress))
+	}
+	if len(s.DNSOpt) > 0 {
+		n.set("dns_opt", yamlList(s.DNSOpt))
+	}
+	if s.ReadOnly {
+		n.set("read_only", yamlPlain("true"))
+	}
+	if len(s.GroupAdd) > 0 {
+		n.set("group_add", yamlList(s.GroupAdd))
+	}
+	if len(s.SecurityOpt) > 0 {
+		n.set("security_opt", yamlList(s.SecurityOpt))
+	}
+	if len(s.Configs) > 0 {
+		configs := &yamlNode{kind: yamlSequence}
+		for _, mount := range s.Configs {
+			if mount.Target == "" {
+				configs.append(yamlPlain(mount.Source))
+				continue
+			}
+			configs.append(yamlMap().set("source", yamlPlain(mount.Source)).set("target", yamlPlain(mount.Target)))
+		}
+		n.set("configs", configs)
+	}
+
+	if hc := s.HealthCheck; hc != nil && hc.Disable {
+		n.set("healthcheck", yamlMap().set("disable", yamlPlain("true")))
+	} else if hc != nil {
+		healthcheck := yamlMap()
+		if len(hc.Test) > 0 {
+			healthcheck.set("test", yamlFlowList(hc.Test))
+		}
+		if hc.Interval != "" {
+			healthcheck.set("interval", yamlPlain(hc.Interval))
+		}
+		if hc.Timeout != "" {
+			healthcheck.set("timeout", yamlPlain(hc.Timeout))
+		}
+		if hc.Retries > 0 {
+			healthcheck.set("retries", yamlPlain(strconv.Itoa(hc.Retries)))
+		}
+		if hc.StartPeriod != "" {
+			healthcheck.set("start_period", yamlPlain(hc.StartPeriod))
+		}
+		n.set("healthcheck", healthcheck)
+	}
+
+	return n
+}
+
+// toYAML builds the YAML node for a service volume entry
+func (v ComposeServiceVolume) toYAML() *yamlNode {
+	if v.Short != "" {
+		return yamlPlain(v.Short)
+	}
+
+	n := yamlMap().set("type", yamlPlain(v.Type))
+	if v.Source != "" {
+		source := yamlPlain(v.Source)
+		if isWindowsPath(v.Source) {
+			// The drive colon and backslashes need quoting
+			source = yamlQuoted(v.Source)
+		}
+		n.set("source", source)
+	}
+	n.set("target", yamlPlain(v.Target))
+	if v.ReadOnly {
+		n.set("read_only", yamlPlain("true"))
+	}
+	if v.Consistency != "" {
+		n.set("consistency", yamlPlain(v.Consistency))
+	}
+	if v.Propagation != "" {
+		n.set("bind", yamlMap().set("propagation", yamlPlain(v.Propagation)))
+	}
+	if v.NoCopy {
+		n.set("volume", yamlMap().set("nocopy", yamlPlain("true")))
+	}
+	return n
+}
+
+// formatDuration renders a duration in compose's format, or "" when it is unset
+func formatDuration(d time.Duration) string {
+	if d <= 0 {
+		return ""
+	}
+	return d.String()
+}
+
+// keyValueList renders a map as sorted KEY=value entries
+func keyValueList(m map[string]string) []string {
+	entries := make([]string, 0, len(m))
+	for _, key := range sortedKeys(m) {
+		entries = append(entries, fmt.Sprintf("%s=%s", key, m[key]))
+	}
+	return entries
+}
+
+// passthroughKeys returns a service's passthrough variables that Environment doesn't set, sorted
+func passthroughKeys(service ServiceConfig) []string {
+	var keys []string
+	for _, key := range service.EnvPassthrough {
+		if _, set := service.Environment[key]; !set {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// yamlLabels renders a labels mapping with its keys in sorted order. Values are quoted
+// so ones such as "true" or "1" stay strings, as docker labels are.
+func yamlLabels(labels map[string]string) *yamlNode {
+	n := yamlMap()
+	for _, key := range sortedKeys(labels) {
+		n.set(key, yamlQuoted(labels[key]))
+	}
+	return n
+}
+
+// sortedServiceNames returns the service names in sorted order
+func sortedServiceNames(services map[string]*ComposeService) []string {
+	names := make([]string, 0, len(services))
+	for name := range services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sortedDependencyNames returns the names in a long-form depends_on in sorted order
+func sortedDependencyNames(deps map[string]ComposeDependency) []string {
+	names := make([]string, 0, len(deps))
+	for name := range deps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ComposeSchemaVersion returns the compose file version the provider will emit, or
+// ComposeSpecVersion when the file follows the versionless compose specification. Without
+// an explicit ComposeVersion it follows the docker-compose binary once Validate has run.
+func (p *DockerComposeProvider) ComposeSchemaVersion() string {
+	p.mu.RLock()
+	config := p.config
+	p.mu.RUnlock()
+
+	return composeSchemaVersion(p.withDetectedComposeVersion(config))
+}
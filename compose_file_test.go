@@ -0,0 +1,183 @@
+package thirdpartyhosting
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderComposeStruct(t *testing.T) {
+	config := ComposeConfig{
+		ProjectName: "fider",
+		Network:     "fider-net",
+		Services: map[string]ServiceConfig{
+			"app": {
+				ImageName:     "getfider/fider",
+				ImageTag:      "stable",
+				RestartPolicy: "always",
+				ExposedPorts:  []PortMapping{{HostPort: 80, ContainerPort: 3000}},
+				Environment:   map[string]string{"BASE_URL": "http://localhost"},
+				DependsOn:     []string{"db"},
+				HealthCheck:   &HealthCheck{Test: []string{"CMD", "true"}, Interval: 10 * time.Second},
+			},
+			"db": {
+				ImageName: "postgres",
+				ImageTag:  "12",
+				Volumes:   []VolumeMapping{{HostPath: "pg_data", ContainerPath: "/var/lib/postgresql/data"}},
+				Resources: ResourceLimits{Memory: "512m"},
+			},
+		},
+	}
+
+	file, err := RenderComposeStruct(config)
+	require.NoError(t, err)
+
+	assert.Equal(t, "3.4", file.Version)
+	require.Len(t, file.Services, 2)
+
+	app := file.Services["app"]
+	assert.Equal(t, "getfider/fider:stable", app.Image)
+	assert.Equal(t, "always", app.Restart)
+	assert.Equal(t, []string{"80:3000/tcp"}, app.Ports)
+	assert.Equal(t, map[string]string{"BASE_URL": "http://localhost"}, app.Environment)
+	assert.Equal(t, []string{"db"}, app.DependsOn)
+	assert.Equal(t, &ComposeHealthCheck{Test: []string{"CMD", "true"}, Interval: "10s"}, app.HealthCheck)
+	assert.Nil(t, app.Deploy)
+
+	db := file.Services["db"]
+	assert.Equal(t, []ComposeServiceVolume{{Short: "pg_data:/var/lib/postgresql/data"}}, db.Volumes)
+	assert.Equal(t, "512m", db.Deploy.Resources.Limits.Memory)
+
+	assert.Equal(t, map[string]ComposeNetwork{"fider-net": {Driver: "bridge"}}, file.Networks)
+	assert.Equal(t, map[string]ComposeVolume{"pg_data": {}}, file.Volumes)
+}
+
+func TestGenerateComposeContentMarshalsStruct(t *testing.T) {
+	config := ComposeConfig{
+		Network: "backend",
+		Services: map[string]ServiceConfig{
+			"web": {ImageName: "nginx", ImageTag: "1.25", ExposedPorts: []PortMapping{{HostPort: 8080, ContainerPort: 80}}},
+			"db":  {ImageName: "postgres", Volumes: []VolumeMapping{{HostPath: "pg_data", ContainerPath: "/data"}}},
+		},
+	}
+
+	content, err := generateComposeContent(config)
+
+	assert.NoError(t, err)
+	assert.Equal(t, `version: "3.4"
+
+services:
+  db:
+    image: postgres
+    volumes:
+      - pg_data:/data
+  web:
+    image: nginx:1.25
+    ports:
+      - "8080:80/tcp"
+
+networks:
+  backend:
+    driver: bridge
+
+volumes:
+  pg_data: {}
+`, content)
+}
+
+func TestGenerateComposeContentNetworkAndVolumeLabels(t *testing.T) {
+	config := ComposeConfig{
+		Network:       "backend",
+		NetworkLabels: map[string]string{"owner": "ci", "com.example.stack": "shop"},
+		VolumeLabels:  map[string]string{"owner": "ci", "backup": "true"},
+		Services: map[string]ServiceConfig{
+			"db": {ImageName: "postgres", Volumes: []VolumeMapping{
+				{HostPath: "pg_data", ContainerPath: "/data"},
+				{HostPath: "./init", ContainerPath: "/docker-entrypoint-initdb.d"},
+			}},
+		},
+	}
+
+	content, err := generateComposeContent(config)
+
+	assert.NoError(t, err)
+	assert.Contains(t, content, `networks:
+  backend:
+    driver: bridge
+    labels:
+      com.example.stack: "shop"
+      owner: "ci"
+
+volumes:
+  pg_data:
+    labels:
+      backup: "true"
+      owner: "ci"
+`)
+}
+
+func TestComposeSchemaVersion(t *testing.T) {
+	provider, _ := newTestProvider(t, testConfig(), nil)
+	assert.Equal(t, "3.4", provider.ComposeSchemaVersion())
+
+	config := testConfig()
+	config.ComposeVersion = "3.8"
+	provider, _ = newTestProvider(t, config, nil)
+	assert.Equal(t, "3.8", provider.ComposeSchemaVersion())
+
+	content, err := generateComposeContent(config)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(content, "version: \"3.8\"\n"))
+}
+
+func TestDetectComposeVersion(t *testing.T) {
+	cases := map[string]string{
+		"docker-compose version 1.29.2, build 5becea4c\ndocker-py version: 5.0.0\n": "3.4",
+		"Docker Compose version v2.24.5\n":                                          ComposeSpecVersion,
+		"Docker Compose version 2.27.0\n":                                           ComposeSpecVersion,
+		"something else\nOpenSSL version: OpenSSL 3.0.2\n":                          "",
+	}
+	for output, expected := range cases {
+		assert.Equal(t, expected, detectComposeVersion([]byte(output)), output)
+	}
+}
+
+func TestComposeSchemaVersionFollowsBinary(t *testing.T) {
+	var command, composeFile string
+	up := captureUp(t, &command, &composeFile)
+	provider, _ := newTestProvider(t, testConfig(), func(ctx context.Context, call string) ([]byte, error) {
+		if call == "docker-compose version" {
+			return []byte("Docker Compose version v2.24.5\n"), nil
+		}
+		return up(ctx, call)
+	})
+	assert.Equal(t, "3.4", provider.ComposeSchemaVersion(), "the default until Validate checks the binary")
+
+	require.NoError(t, provider.Validate(context.Background()))
+	assert.Equal(t, ComposeSpecVersion, provider.ComposeSchemaVersion())
+
+	require.NoError(t, provider.Start(context.Background()))
+	assert.True(t, strings.HasPrefix(composeFile, "services:\n"), composeFile)
+
+	// An explicit version still wins
+	config := testConfig()
+	config.ComposeVersion = "3.8"
+	require.NoError(t, provider.Initialize(context.Background(), config))
+	assert.Equal(t, "3.8", provider.ComposeSchemaVersion())
+}
+
+func TestComposeSchemaVersionSpec(t *testing.T) {
+	config := testConfig()
+	config.ComposeVersion = ComposeSpecVersion
+	provider, _ := newTestProvider(t, config, nil)
+
+	content, err := generateComposeContent(config)
+
+	require.NoError(t, err)
+	assert.Equal(t, ComposeSpecVersion, provider.ComposeSchemaVersion())
+	assert.True(t, strings.HasPrefix(content, "services:\n"))
+}
@@ -5,11 +5,24 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 )
 
-// generateComposeFile creates a temporary docker-compose.yml file from the config
-func generateComposeFile(config ComposeConfig) (string, error) {
+// defaultComposeFileName is the name of generated compose files unless the provider sets ComposeFileName
+const defaultComposeFileName = "docker-compose.yml"
+
+// generateComposeFile writes the config to a compose file named name, or
+// defaultComposeFileName when name is empty, in a new temporary directory
+func generateComposeFile(config ComposeConfig, name string) (string, error) {
+	if name == "" {
+		name = defaultComposeFileName
+	}
+	if filepath.Base(name) != name || name == "." || name == ".." {
+		return "", fmt.Errorf("compose file name %q must be a file name without directories", name)
+	}
+
 	// Create a temporary directory for the compose file
 	tempDir, err := ioutil.TempDir("", "docker-compose-")
 	if err != nil {
@@ -23,7 +36,7 @@ func generateComposeFile(config ComposeConfig) (string, error) {
 	}
 
 	// Write the content to a file
-	composeFilePath := filepath.Join(tempDir, "docker-compose.yml")
+	composeFilePath := filepath.Join(tempDir, name)
 	if err := ioutil.WriteFile(composeFilePath, []byte(content), 0644); err != nil {
 		return "", fmt.Errorf("failed to write compose file: %w", err)
 	}
@@ -33,76 +46,97 @@ func generateComposeFile(config ComposeConfig) (string, error) {
 
 // generateComposeContent creates the content for a docker-compose.yml file
 func generateComposeContent(config ComposeConfig) (string, error) {
-	var sb strings.Builder
+	file, err := RenderComposeStruct(config)
+	if err != nil {
+		return "", err
+	}
 
-	// Write the version
-	sb.WriteString("version: \"3.4\"\n\n")
+	return file.marshal(config.RenderOptions), nil
+}
 
-	// Write the services section
-	sb.WriteString("services:\n")
-	for serviceName, serviceConfig := range config.Services {
-		sb.WriteString(fmt.Sprintf("  %s:\n", serviceName))
-		sb.WriteString(fmt.Sprintf("    image: %s:%s\n", serviceConfig.ImageName, serviceConfig.ImageTag))
+// imageRef returns the image reference for a service, omitting the tag when none is set
+func imageRef(service ServiceConfig) string {
+	if service.ImageTag == "" {
+		return service.ImageName
+	}
+	return fmt.Sprintf("%s:%s", service.ImageName, service.ImageTag)
+}
 
-		// Write restart policy if specified
-		if serviceConfig.RestartPolicy != "" {
-			sb.WriteString(fmt.Sprintf("    restart: %s\n", serviceConfig.RestartPolicy))
-		}
+// sortedKeys returns the keys of m in sorted order so rendering is deterministic
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
 
-		// Write port mappings if any
-		if len(serviceConfig.ExposedPorts) > 0 {
-			sb.WriteString("    ports:\n")
-			for _, port := range serviceConfig.ExposedPorts {
-				sb.WriteString(fmt.Sprintf("      - \"%d:%d/%s\"\n", port.HostPort, port.ContainerPort, port.Protocol))
-			}
-		}
+// volumeMappingType returns the mapping's explicit Type, or infers bind for host
+// paths and volume for named volumes
+func volumeMappingType(volume VolumeMapping) string {
+	if volume.Type != "" {
+		return volume.Type
+	}
+	if isHostPath(volume.HostPath) {
+		return "bind"
+	}
+	return "volume"
+}
 
-		// Write volume mappings if any
-		if len(serviceConfig.Volumes) > 0 {
-			sb.WriteString("    volumes:\n")
-			for _, volume := range serviceConfig.Volumes {
-				sb.WriteString(fmt.Sprintf("      - %s:%s\n", volume.HostPath, volume.ContainerPath))
-			}
+// resolveRelativePath joins a relative path onto baseDir, or onto the working directory
+// when baseDir is empty, since the generated compose file lives in a temporary directory.
+// Absolute, home-relative and Windows paths are returned unchanged.
+func resolveRelativePath(baseDir, path string) string {
+	if path == "" || filepath.IsAbs(path) || strings.HasPrefix(path, "~") || isWindowsPath(path) {
+		return path
+	}
+	if baseDir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return path
 		}
+		baseDir = wd
+	}
+	return filepath.Join(baseDir, path)
+}
 
-		// Write environment variables if any
-		if len(serviceConfig.Environment) > 0 {
-			sb.WriteString("    environment:\n")
-			for key, value := range serviceConfig.Environment {
-				sb.WriteString(fmt.Sprintf("      - %s=%s\n", key, value))
-			}
-		}
+// resolveBuildContext resolves a local build context against baseDir, leaving remote contexts untouched
+func resolveBuildContext(baseDir, context string) string {
+	if strings.Contains(context, "://") || strings.HasPrefix(context, "git@") {
+		return context
+	}
+	return resolveRelativePath(baseDir, context)
+}
 
-		// Write dependencies if any
-		if len(serviceConfig.DependsOn) > 0 {
-			sb.WriteString("    depends_on:\n")
-			for _, dep := range serviceConfig.DependsOn {
-				sb.WriteString(fmt.Sprintf("      - %s\n", dep))
-			}
-		}
+// isHostPath reports whether path refers to a host filesystem location rather than a named volume
+func isHostPath(path string) bool {
+	return strings.HasPrefix(path, "/") || strings.HasPrefix(path, ".") ||
+		strings.HasPrefix(path, "~") || isWindowsPath(path)
+}
 
-		// Write resource limits if specified
-		if serviceConfig.Resources.Memory != "" || serviceConfig.Resources.CPUShare != "" {
-			sb.WriteString("    deploy:\n")
-			sb.WriteString("      resources:\n")
-			sb.WriteString("        limits:\n")
-			if serviceConfig.Resources.Memory != "" {
-				sb.WriteString(fmt.Sprintf("          memory: %s\n", serviceConfig.Resources.Memory))
-			}
-			if serviceConfig.Resources.CPUShare != "" {
-				sb.WriteString(fmt.Sprintf("          cpus: %s\n", serviceConfig.Resources.CPUShare))
-			}
-		}
+// isWindowsPath reports whether path is a Windows drive path (C:\data, C:/data) or a UNC path (\\server\share)
+func isWindowsPath(path string) bool {
+	if strings.HasPrefix(path, `\\`) {
+		return true
 	}
+	return len(path) >= 3 &&
+		((path[0] >= 'a' && path[0] <= 'z') || (path[0] >= 'A' && path[0] <= 'Z')) &&
+		path[1] == ':' && (path[2] == '\\' || path[2] == '/')
+}
 
-	// Write the networks section if a network is specified
-	if config.Network != "" {
-		sb.WriteString("\nnetworks:\n")
-		sb.WriteString(fmt.Sprintf("  %s:\n", config.Network))
-		sb.WriteString("    driver: bridge\n")
+// portSpec renders a port mapping in compose short syntax. A HostPort of 0 renders
+// only the container port so Docker assigns a random host port.
+func portSpec(port PortMapping) string {
+	protocol := portProtocol(port)
+	if port.HostPort == 0 {
+		if protocol == defaultProtocol {
+			return strconv.Itoa(port.ContainerPort)
+		}
+		return fmt.Sprintf("%d/%s", port.ContainerPort, protocol)
 	}
 
-	return sb.String(), nil
+	return fmt.Sprintf("%d:%d/%s", port.HostPort, port.ContainerPort, protocol)
 }
 
 // CleanupComposeFile removes the temporary docker-compose.yml file
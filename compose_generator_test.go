@@ -0,0 +1,709 @@
+package thirdpartyhosting
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestGenerateComposeContentSCTPPort(t *testing.T) {
+	config := ComposeConfig{
+		Services: map[string]ServiceConfig{
+			"diameter": {
+				ImageName: "diameter-agent",
+				ImageTag:  "latest",
+				ExposedPorts: []PortMapping{
+					{HostPort: 3868, ContainerPort: 3868, Protocol: "sctp"},
+				},
+			},
+		},
+	}
+
+	content, err := generateComposeContent(config)
+
+	assert.NoError(t, err)
+	assert.Contains(t, content, "- \"3868:3868/sctp\"\n")
+}
+
+func TestGenerateComposeContentDefaultProtocol(t *testing.T) {
+	config := ComposeConfig{
+		Services: map[string]ServiceConfig{
+			"web": {
+				ImageName:    "nginx",
+				ImageTag:     "latest",
+				ExposedPorts: []PortMapping{{HostPort: 8080, ContainerPort: 80}},
+			},
+		},
+	}
+
+	content, err := generateComposeContent(config)
+
+	assert.NoError(t, err)
+	assert.Contains(t, content, "- \"8080:80/tcp\"\n")
+}
+
+func TestGenerateComposeContentHealthCheck(t *testing.T) {
+	config := ComposeConfig{
+		Services: map[string]ServiceConfig{
+			"web": {
+				ImageName: "nginx",
+				ImageTag:  "latest",
+				HealthCheck: &HealthCheck{
+					Test:        []string{"CMD", "curl", "-f", "http://localhost/health"},
+					Interval:    30 * time.Second,
+					Timeout:     5 * time.Second,
+					Retries:     3,
+					StartPeriod: 10 * time.Second,
+				},
+			},
+		},
+	}
+
+	content, err := generateComposeContent(config)
+
+	assert.NoError(t, err)
+	assert.Contains(t, content, "    healthcheck:\n      test: [\"CMD\", \"curl\", \"-f\", \"http://localhost/health\"]\n")
+	assert.Contains(t, content, "      interval: 30s\n")
+	assert.Contains(t, content, "      timeout: 5s\n")
+	assert.Contains(t, content, "      retries: 3\n")
+	assert.Contains(t, content, "      start_period: 10s\n")
+}
+
+func TestGenerateComposeContentHealthCheckForms(t *testing.T) {
+	config := ComposeConfig{
+		Services: map[string]ServiceConfig{
+			"exec":     {ImageName: "app", HealthCheck: &HealthCheck{Test: []string{"curl", "-f", "http://localhost/health"}}},
+			"explicit": {ImageName: "app", HealthCheck: &HealthCheck{Test: []string{"CMD", "true"}}},
+			"shell":    {ImageName: "postgres", HealthCheck: &HealthCheck{Shell: "pg_isready -U postgres || exit 1"}},
+			"none":     {ImageName: "app", HealthCheck: &HealthCheck{Test: []string{"NONE"}}},
+			"timing":   {ImageName: "app", HealthCheck: &HealthCheck{Interval: 5 * time.Second}},
+		},
+	}
+
+	content, err := generateComposeContent(config)
+
+	assert.NoError(t, err)
+	assert.Contains(t, content, "  exec:\n    image: app\n    healthcheck:\n      test: [\"CMD\", \"curl\", \"-f\", \"http://localhost/health\"]\n")
+	assert.Contains(t, content, "  explicit:\n    image: app\n    healthcheck:\n      test: [\"CMD\", \"true\"]\n")
+	assert.Contains(t, content, "  shell:\n    image: postgres\n    healthcheck:\n      test: [\"CMD-SHELL\", \"pg_isready -U postgres || exit 1\"]\n")
+	assert.Contains(t, content, "  none:\n    image: app\n    healthcheck:\n      test: [\"NONE\"]\n")
+	assert.Contains(t, content, "  timing:\n    image: app\n    healthcheck:\n      interval: 5s\n")
+}
+
+func TestGenerateComposeContentSwapControls(t *testing.T) {
+	swappiness := 10
+	config := ComposeConfig{
+		Services: map[string]ServiceConfig{
+			"cache": {
+				ImageName: "redis",
+				ImageTag:  "7",
+				Resources: ResourceLimits{
+					Memory:        "512m",
+					MemSwapLimit:  "1g",
+					MemSwappiness: &swappiness,
+				},
+			},
+		},
+	}
+
+	content, err := generateComposeContent(config)
+
+	assert.NoError(t, err)
+	assert.Contains(t, content, "\n    memswap_limit: 1g\n")
+	assert.Contains(t, content, "\n    mem_swappiness: 10\n")
+	assert.Contains(t, content, "          memory: 512m\n")
+}
+
+func TestGenerateComposeContentOOMControls(t *testing.T) {
+	scoreAdj := -500
+	config := ComposeConfig{
+		Services: map[string]ServiceConfig{
+			"db": {
+				ImageName: "postgres",
+				Resources: ResourceLimits{OOMKillDisable: true, OOMScoreAdj: &scoreAdj},
+			},
+		},
+	}
+
+	content, err := generateComposeContent(config)
+
+	assert.NoError(t, err)
+	assert.Contains(t, content, "\n    oom_kill_disable: true\n")
+	assert.Contains(t, content, "\n    oom_score_adj: -500\n")
+}
+
+func TestGenerateComposeContentCPUSet(t *testing.T) {
+	config := ComposeConfig{
+		Services: map[string]ServiceConfig{
+			"trader": {
+				ImageName: "trader",
+				ImageTag:  "latest",
+				Resources: ResourceLimits{CPUSet: "0-3"},
+			},
+		},
+	}
+
+	content, err := generateComposeContent(config)
+
+	assert.NoError(t, err)
+	assert.Contains(t, content, "\n    cpuset: \"0-3\"\n")
+}
+
+func TestGenerateComposeContentEphemeralHostPort(t *testing.T) {
+	config := ComposeConfig{
+		Services: map[string]ServiceConfig{
+			"web": {
+				ImageName: "nginx",
+				ImageTag:  "latest",
+				ExposedPorts: []PortMapping{
+					{HostPort: 0, ContainerPort: 80, Protocol: "tcp"},
+					{HostPort: 0, ContainerPort: 53, Protocol: "udp"},
+				},
+			},
+		},
+	}
+
+	content, err := generateComposeContent(config)
+
+	assert.NoError(t, err)
+	assert.Contains(t, content, "      - \"80\"\n")
+	assert.Contains(t, content, "      - \"53/udp\"\n")
+	assert.NotContains(t, content, "\"0:")
+}
+
+func TestGenerateComposeContentMultiStageBuild(t *testing.T) {
+	config := ComposeConfig{
+		BaseDir: "/srv/stack",
+		Services: map[string]ServiceConfig{
+			"api": {
+				ImageName: "registry.example.com/api",
+				ImageTag:  "dev",
+				Build: &BuildConfig{
+					Context:    "./api",
+					Dockerfile: "Dockerfile.multi",
+					Args:       map[string]string{"GO_VERSION": "1.22"},
+					Target:     "production",
+					CacheFrom:  []string{"registry.example.com/api:cache"},
+				},
+			},
+		},
+	}
+
+	content, err := generateComposeContent(config)
+
+	assert.NoError(t, err)
+	assert.Contains(t, content, "    image: registry.example.com/api:dev\n")
+	assert.Contains(t, content, "    build:\n"+
+		"      context: /srv/stack/api\n"+
+		"      dockerfile: Dockerfile.multi\n"+
+		"      args:\n"+
+		"        - GO_VERSION=1.22\n"+
+		"      target: production\n"+
+		"      cache_from:\n"+
+		"        - registry.example.com/api:cache\n")
+}
+
+func TestGenerateComposeContentBuildWithoutImage(t *testing.T) {
+	config := ComposeConfig{
+		BaseDir: "/srv/stack",
+		Services: map[string]ServiceConfig{
+			"api": {Build: &BuildConfig{Context: "."}},
+		},
+	}
+
+	content, err := generateComposeContent(config)
+
+	assert.NoError(t, err)
+	assert.NotContains(t, content, "image:")
+	assert.Contains(t, content, "      context: /srv/stack\n")
+}
+
+func TestGenerateComposeContentWindowsVolumePath(t *testing.T) {
+	config := ComposeConfig{
+		Services: map[string]ServiceConfig{
+			"app": {
+				ImageName: "app",
+				Volumes: []VolumeMapping{
+					{HostPath: `C:\Users\x`, ContainerPath: "/data"},
+					{HostPath: "/var/app", ContainerPath: "/app"},
+				},
+			},
+		},
+	}
+
+	content, err := generateComposeContent(config)
+
+	assert.NoError(t, err)
+	assert.Contains(t, content, "      - type: bind\n"+
+		"        source: \"C:\\\\Users\\\\x\"\n"+
+		"        target: /data\n")
+	assert.Contains(t, content, "      - /var/app:/app\n")
+	assert.NotContains(t, content, `C:\Users\x:/data`)
+}
+
+func TestIsWindowsPath(t *testing.T) {
+	assert.True(t, isWindowsPath(`C:\Users\x`))
+	assert.True(t, isWindowsPath("d:/data"))
+	assert.True(t, isWindowsPath(`\\server\share`))
+	assert.False(t, isWindowsPath("/var/data"))
+	assert.False(t, isWindowsPath("./data"))
+	assert.False(t, isWindowsPath("named-volume"))
+}
+
+func TestGenerateComposeContentShortVsLongVolumeSyntax(t *testing.T) {
+	volumes := []VolumeMapping{
+		{HostPath: "/srv/config", ContainerPath: "/etc/app", ReadOnly: true},
+		{HostPath: "app_data", ContainerPath: "/var/lib/app"},
+	}
+	config := ComposeConfig{
+		Services: map[string]ServiceConfig{
+			"app": {ImageName: "app", Volumes: volumes},
+		},
+	}
+
+	short, err := generateComposeContent(config)
+	assert.NoError(t, err)
+	assert.Contains(t, short, "    volumes:\n"+
+		"      - /srv/config:/etc/app:ro\n"+
+		"      - app_data:/var/lib/app\n")
+
+	config.UseLongVolumeSyntax = true
+	long, err := generateComposeContent(config)
+	assert.NoError(t, err)
+	assert.Contains(t, long, "    volumes:\n"+
+		"      - type: bind\n"+
+		"        source: /srv/config\n"+
+		"        target: /etc/app\n"+
+		"        read_only: true\n"+
+		"      - type: volume\n"+
+		"        source: app_data\n"+
+		"        target: /var/lib/app\n")
+}
+
+func TestGenerateComposeContentAdvancedVolumeOptions(t *testing.T) {
+	config := ComposeConfig{
+		BaseDir: "/srv/stack",
+		Services: map[string]ServiceConfig{
+			"app": {
+				ImageName: "app",
+				Volumes: []VolumeMapping{
+					{HostPath: "./src", ContainerPath: "/src", Propagation: "rshared", Consistency: "cached"},
+					{HostPath: "cache", ContainerPath: "/cache", NoCopy: true},
+					{Type: "tmpfs", ContainerPath: "/tmp"},
+					{HostPath: "/plain", ContainerPath: "/plain"},
+				},
+			},
+		},
+	}
+
+	content, err := generateComposeContent(config)
+
+	assert.NoError(t, err)
+	assert.Contains(t, content, "      - type: bind\n"+
+		"        source: /srv/stack/src\n"+
+		"        target: /src\n"+
+		"        consistency: cached\n"+
+		"        bind:\n"+
+		"          propagation: rshared\n")
+	assert.Contains(t, content, "      - type: volume\n"+
+		"        source: cache\n"+
+		"        target: /cache\n"+
+		"        volume:\n"+
+		"          nocopy: true\n")
+	assert.Contains(t, content, "      - type: tmpfs\n"+
+		"        target: /tmp\n")
+	assert.Contains(t, content, "      - /plain:/plain\n")
+}
+
+func TestGenerateComposeContentResolvesPathsAgainstBaseDir(t *testing.T) {
+	config := ComposeConfig{
+		BaseDir: "/srv/stack",
+		Services: map[string]ServiceConfig{
+			"app": {
+				Build: &BuildConfig{Context: "./app"},
+				Volumes: []VolumeMapping{
+					{HostPath: "./data", ContainerPath: "/data"},
+					{HostPath: "../shared", ContainerPath: "/shared"},
+					{HostPath: "/abs", ContainerPath: "/abs"},
+					{HostPath: "named", ContainerPath: "/named"},
+				},
+			},
+			"remote": {
+				Build: &BuildConfig{Context: "https://github.com/example/repo.git"},
+			},
+		},
+	}
+
+	content, err := generateComposeContent(config)
+
+	assert.NoError(t, err)
+	assert.Contains(t, content, "      context: /srv/stack/app\n")
+	assert.Contains(t, content, "      - /srv/stack/data:/data\n")
+	assert.Contains(t, content, "      - /srv/shared:/shared\n")
+	assert.Contains(t, content, "      - /abs:/abs\n")
+	assert.Contains(t, content, "      - named:/named\n")
+	assert.Contains(t, content, "      context: https://github.com/example/repo.git\n")
+}
+
+func TestGenerateComposeContentCgroup(t *testing.T) {
+	config := ComposeConfig{
+		Services: map[string]ServiceConfig{
+			"worker": {
+				ImageName:    "worker",
+				CgroupParent: "/latency-critical",
+				Cgroup:       "private",
+			},
+		},
+	}
+
+	content, err := generateComposeContent(config)
+
+	assert.NoError(t, err)
+	assert.Contains(t, content, "\n    cgroup_parent: /latency-critical\n")
+	assert.Contains(t, content, "\n    cgroup: private\n")
+}
+
+func TestGenerateComposeContentDeployRestartPolicy(t *testing.T) {
+	config := ComposeConfig{
+		Services: map[string]ServiceConfig{
+			"worker": {
+				ImageName: "worker",
+				Resources: ResourceLimits{Memory: "256m"},
+				Deploy: &DeployConfig{RestartPolicy: &DeployRestartPolicy{
+					Condition:   "on-failure",
+					Delay:       5 * time.Second,
+					MaxAttempts: 3,
+					Window:      2 * time.Minute,
+				}},
+			},
+		},
+	}
+
+	content, err := generateComposeContent(config)
+
+	assert.NoError(t, err)
+	assert.Contains(t, content, "    deploy:\n"+
+		"      resources:\n"+
+		"        limits:\n"+
+		"          memory: 256m\n"+
+		"      restart_policy:\n"+
+		"        condition: on-failure\n"+
+		"        delay: 5s\n"+
+		"        max_attempts: 3\n"+
+		"        window: 2m0s\n")
+}
+
+func TestGenerateComposeContentPullPolicy(t *testing.T) {
+	config := ComposeConfig{
+		Services: map[string]ServiceConfig{
+			"app": {ImageName: "app", ImageTag: "latest", PullPolicy: "always"},
+		},
+	}
+
+	content, err := generateComposeContent(config)
+
+	assert.NoError(t, err)
+	assert.Contains(t, content, "    image: app:latest\n    pull_policy: always\n")
+
+	config.Services["app"] = ServiceConfig{ImageName: "app", PullPolicy: "sometimes"}
+	assert.Error(t, ValidateConfig(config))
+}
+
+func TestGenerateComposeContentMacAddress(t *testing.T) {
+	config := ComposeConfig{
+		Services: map[string]ServiceConfig{
+			"licensed": {ImageName: "licensed", MacAddress: "02:42:ac:11:00:02"},
+		},
+	}
+
+	content, err := generateComposeContent(config)
+
+	assert.NoError(t, err)
+	assert.Contains(t, content, "    mac_address: \"02:42:ac:11:00:02\"\n")
+}
+
+func TestGenerateComposeContentReadOnlyRootFS(t *testing.T) {
+	config := ComposeConfig{
+		Services: map[string]ServiceConfig{
+			"api": {
+				ImageName:      "api",
+				ReadOnlyRootFS: true,
+				Volumes:        []VolumeMapping{{Type: "tmpfs", ContainerPath: "/tmp"}},
+			},
+		},
+	}
+
+	content, err := generateComposeContent(config)
+
+	assert.NoError(t, err)
+	assert.Contains(t, content, "\n    read_only: true\n")
+}
+
+func TestGenerateComposeContentLinks(t *testing.T) {
+	config := ComposeConfig{
+		Services: map[string]ServiceConfig{
+			"app": {ImageName: "app", Links: []string{"db:database", "cache"}},
+			"db":  {ImageName: "postgres"},
+		},
+	}
+
+	content, err := generateComposeContent(config)
+
+	assert.NoError(t, err)
+	assert.Contains(t, content, "    links:\n      - db:database\n      - cache\n")
+}
+
+func TestGenerateComposeContentEntrypointAndCommand(t *testing.T) {
+	config := ComposeConfig{
+		Services: map[string]ServiceConfig{
+			"worker": {ImageName: "app", Entrypoint: []string{"/bin/sh", "-c"}, Command: []string{"exec worker --queue=default"}},
+		},
+	}
+
+	content, err := generateComposeContent(config)
+
+	assert.NoError(t, err)
+	assert.Contains(t, content, "    entrypoint: [\"/bin/sh\", \"-c\"]\n")
+	assert.Contains(t, content, "    command: [\"exec worker --queue=default\"]\n")
+}
+
+func TestGenerateComposeContentEntrypointNilVersusEmpty(t *testing.T) {
+	config := ComposeConfig{
+		Services: map[string]ServiceConfig{
+			"untouched": {ImageName: "app"},
+			"cleared":   {ImageName: "app", Entrypoint: []string{}, Command: []string{}},
+		},
+	}
+
+	file, err := RenderComposeStruct(config)
+	assert.NoError(t, err)
+	content := file.marshal(config.RenderOptions)
+
+	assert.Equal(t, 1, strings.Count(content, "entrypoint:"))
+	assert.Contains(t, content, "  cleared:\n    image: app\n    entrypoint: []\n    command: []\n")
+	assert.NotContains(t, file.Services["untouched"].toYAML().keys, "entrypoint")
+}
+
+func TestGenerateComposeContentShellFormCommand(t *testing.T) {
+	config := ComposeConfig{
+		Services: map[string]ServiceConfig{
+			"shell": {ImageName: "node", CommandShell: "npm start", EntrypointShell: "docker-entrypoint.sh"},
+			"exec":  {ImageName: "node", Command: []string{"npm", "start"}},
+		},
+	}
+
+	content, err := generateComposeContent(config)
+
+	assert.NoError(t, err)
+	assert.Contains(t, content, "  shell:\n    image: node\n    entrypoint: \"docker-entrypoint.sh\"\n    command: \"npm start\"\n")
+	assert.Contains(t, content, "  exec:\n    image: node\n    command: [\"npm\", \"start\"]\n")
+
+	var doc map[string]interface{}
+	require.NoError(t, yaml.Unmarshal([]byte(content), &doc))
+	services := doc["services"].(map[string]interface{})
+	assert.Equal(t, "npm start", services["shell"].(map[string]interface{})["command"])
+	assert.Equal(t, []interface{}{"npm", "start"}, services["exec"].(map[string]interface{})["command"])
+}
+
+func TestGenerateComposeContentDisableHealthCheck(t *testing.T) {
+	config := ComposeConfig{
+		Services: map[string]ServiceConfig{
+			"app": {
+				ImageName:          "app",
+				DisableHealthCheck: true,
+				HealthCheck:        &HealthCheck{Test: []string{"CMD", "true"}, Retries: 3},
+			},
+		},
+	}
+
+	content, err := generateComposeContent(config)
+
+	assert.NoError(t, err)
+	assert.Contains(t, content, "    healthcheck:\n      disable: true\n")
+	assert.NotContains(t, content, "test:")
+	assert.NotContains(t, content, "retries:")
+	assert.Len(t, ConfigWarnings(config), 1)
+}
+
+func TestGenerateComposeContentRuntime(t *testing.T) {
+	config := ComposeConfig{
+		Services: map[string]ServiceConfig{
+			"inference": {ImageName: "model-server", Runtime: "nvidia"},
+			"sandbox":   {ImageName: "untrusted", Runtime: "runsc"},
+		},
+	}
+
+	content, err := generateComposeContent(config)
+
+	assert.NoError(t, err)
+	assert.Contains(t, content, "  inference:\n    image: model-server\n    runtime: nvidia\n")
+	assert.Contains(t, content, "  sandbox:\n    image: untrusted\n    runtime: runsc\n")
+}
+
+func TestGenerateComposeContentIsolation(t *testing.T) {
+	config := ComposeConfig{
+		Services: map[string]ServiceConfig{
+			"iis": {ImageName: "mcr.microsoft.com/windows/servercore/iis", Isolation: "hyperv"},
+		},
+	}
+
+	content, err := generateComposeContent(config)
+
+	assert.NoError(t, err)
+	assert.Contains(t, content, "\n    isolation: hyperv\n")
+}
+
+func TestGenerateComposeContentExternalLinks(t *testing.T) {
+	config := ComposeConfig{
+		Services: map[string]ServiceConfig{
+			"app": {ImageName: "app", ExternalLinks: []string{"shared-postgres:db"}},
+		},
+	}
+
+	content, err := generateComposeContent(config)
+
+	assert.NoError(t, err)
+	assert.NoError(t, ValidateConfig(config))
+	assert.Contains(t, content, "    external_links:\n      - shared-postgres:db\n")
+}
+
+func TestGenerateComposeContentStdinOpenAndTTY(t *testing.T) {
+	config := ComposeConfig{
+		Services: map[string]ServiceConfig{
+			"debug": {ImageName: "busybox", Command: []string{"sh"}, StdinOpen: true, TTY: true},
+		},
+	}
+
+	content, err := generateComposeContent(config)
+
+	assert.NoError(t, err)
+	assert.Contains(t, content, "    command: [\"sh\"]\n    stdin_open: true\n    tty: true\n")
+}
+
+func TestGenerateComposeContentResolvesPathsAgainstWorkingDir(t *testing.T) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+
+	config := ComposeConfig{
+		Services: map[string]ServiceConfig{
+			"app": {
+				ImageName: "app",
+				Volumes: []VolumeMapping{
+					{HostPath: "./data", ContainerPath: "/data"},
+					{HostPath: "../shared", ContainerPath: "/shared"},
+					{HostPath: "named", ContainerPath: "/named"},
+				},
+			},
+		},
+	}
+
+	content, err := generateComposeContent(config)
+
+	assert.NoError(t, err)
+	assert.Contains(t, content, "      - "+filepath.Join(wd, "data")+":/data\n")
+	assert.Contains(t, content, "      - "+filepath.Join(filepath.Dir(wd), "shared")+":/shared\n")
+	assert.Contains(t, content, "      - named:/named\n")
+}
+
+func TestGenerateComposeContentBlkioConfig(t *testing.T) {
+	config := ComposeConfig{
+		Services: map[string]ServiceConfig{
+			"db": {
+				ImageName: "postgres",
+				BlkioConfig: &BlkioConfig{
+					Weight:         500,
+					DeviceWriteBps: []BlkioDeviceRate{{Path: "/dev/sdb", Rate: "12mb"}},
+				},
+			},
+		},
+	}
+
+	content, err := generateComposeContent(config)
+
+	assert.NoError(t, err)
+	assert.Contains(t, content, "\n    blkio_config:\n      weight: 500\n      device_write_bps:\n        - path: /dev/sdb\n          rate: \"12mb\"\n")
+	assert.NotContains(t, content, "device_read_bps")
+}
+
+func TestGenerateComposeContentGroupAdd(t *testing.T) {
+	config := ComposeConfig{
+		Services: map[string]ServiceConfig{
+			"ci": {
+				ImageName: "docker",
+				Volumes:   []VolumeMapping{{HostPath: "/var/run/docker.sock", ContainerPath: "/var/run/docker.sock"}},
+				GroupAdd:  []string{"docker", "999"},
+			},
+		},
+	}
+
+	content, err := generateComposeContent(config)
+
+	assert.NoError(t, err)
+	assert.Contains(t, content, "\n    group_add:\n      - docker\n      - 999\n")
+}
+
+func TestGenerateComposeContentDNSOpt(t *testing.T) {
+	config := ComposeConfig{
+		Services: map[string]ServiceConfig{
+			"app": {ImageName: "app", DNSOpt: []string{"ndots:2", "timeout:3"}},
+		},
+	}
+
+	content, err := generateComposeContent(config)
+
+	assert.NoError(t, err)
+	assert.Contains(t, content, "\n    dns_opt:\n      - ndots:2\n      - timeout:3\n")
+}
+
+func TestGenerateComposeContentVolumesFromReadOnly(t *testing.T) {
+	config := ComposeConfig{
+		Services: map[string]ServiceConfig{
+			"data":   {ImageName: "busybox", Volumes: []VolumeMapping{{HostPath: "shared", ContainerPath: "/data"}}},
+			"backup": {ImageName: "backup", VolumesFrom: []string{"data:ro"}},
+		},
+	}
+
+	content, err := generateComposeContent(config)
+
+	assert.NoError(t, err)
+	assert.Contains(t, content, "  backup:\n    image: backup\n    volumes_from:\n      - data:ro\n")
+}
+
+func TestEnvironmentValuesRoundTrip(t *testing.T) {
+	environment := map[string]string{
+		"EQUALS":   "a=b",
+		"COMMAS":   "val,with,commas",
+		"TRAILING": "padded  ",
+		"LEADING":  "  padded",
+		"COMMENT":  "value #not-a-comment",
+		"COLON":    "key: value",
+		"QUOTES":   `say "hi" and 'bye'`,
+		"EMPTY":    "",
+		"BRACKETS": "[1, 2]",
+	}
+	config := ComposeConfig{
+		Services: map[string]ServiceConfig{
+			"app": {ImageName: "app", Environment: environment},
+		},
+	}
+
+	composeFile, err := generateComposeFile(config, "")
+	require.NoError(t, err)
+	defer os.RemoveAll(filepath.Dir(composeFile))
+
+	content, err := os.ReadFile(composeFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "      - EQUALS=a=b\n")
+	assert.Contains(t, string(content), "      - COMMAS=val,with,commas\n")
+	assert.Contains(t, string(content), "      - \"TRAILING=padded  \"\n")
+
+	loaded, err := LoadComposeConfig(composeFile)
+	require.NoError(t, err)
+	assert.Equal(t, environment, loaded.Services["app"].Environment)
+}
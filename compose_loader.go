@@ -0,0 +1,582 @@
+package thirdpartyhosting
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LoadComposeConfig reads existing compose files into a ComposeConfig. Several paths, such
+// as docker-compose.yml followed by docker-compose.override.yml, are merged in order with
+// MergeConfigs. Relative paths in the result resolve against the first file's directory
+// through BaseDir. Service keys the loader can't read yet, such as healthcheck or deploy,
+// are an error rather than dropped; keys compose defines that ServiceConfig has no field
+// for at all are ignored. Each file is validated on its own, with errors naming it, and
+// the merged result is validated again.
+func LoadComposeConfig(paths ...string) (ComposeConfig, error) {
+	if len(paths) == 0 {
+		return ComposeConfig{}, fmt.Errorf("no compose files given")
+	}
+
+	var config ComposeConfig
+	for i, path := range paths {
+		loaded, err := loadComposeFile(path)
+		if err == nil {
+			// Each file is checked on its own so errors name it, leaving what needs the
+			// other files, such as a service's image, to the merged result
+			err = validateConfig(loaded, len(paths) > 1)
+		}
+		if err != nil {
+			return ComposeConfig{}, fmt.Errorf("compose file %s: %w", path, err)
+		}
+
+		if i == 0 {
+			config = loaded
+		} else {
+			config = MergeConfigs(config, loaded)
+		}
+	}
+
+	if len(paths) > 1 {
+		if err := ValidateConfig(config); err != nil {
+			return ComposeConfig{}, fmt.Errorf("compose files %s merged: %w", strings.Join(paths, ", "), err)
+		}
+	}
+
+	return config, nil
+}
+
+// loadComposeFile reads a single compose file
+func loadComposeFile(path string) (ComposeConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ComposeConfig{}, err
+	}
+
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return ComposeConfig{}, fmt.Errorf("failed to parse: %w", err)
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return ComposeConfig{}, fmt.Errorf("failed to resolve path: %w", err)
+	}
+
+	config := ComposeConfig{
+		BaseDir:  filepath.Dir(absPath),
+		Services: make(map[string]ServiceConfig),
+	}
+	if name, ok := doc["name"].(string); ok {
+		config.ProjectName = name
+	}
+	if version, ok := scalarString(doc["version"]); ok {
+		config.ComposeVersion = version
+	}
+
+	services, ok := doc["services"].(map[string]interface{})
+	if !ok && doc["services"] != nil {
+		return ComposeConfig{}, fmt.Errorf("services must be a mapping")
+	}
+	for name, raw := range services {
+		fields, ok := raw.(map[string]interface{})
+		if !ok && raw != nil {
+			return ComposeConfig{}, fmt.Errorf("service %s: must be a mapping", name)
+		}
+
+		service, err := loadService(fields)
+		if err != nil {
+			return ComposeConfig{}, fmt.Errorf("service %s: %w", name, err)
+		}
+		config.Services[name] = service
+	}
+
+	return config, nil
+}
+
+// loadService converts a parsed service mapping into a ServiceConfig
+func loadService(fields map[string]interface{}) (ServiceConfig, error) {
+	var service ServiceConfig
+	var err error
+
+	if image, ok := fields["image"].(string); ok {
+		service.ImageName, service.ImageTag = splitImageRef(image)
+	}
+	if restart, ok := fields["restart"].(string); ok {
+		service.RestartPolicy = restart
+	}
+
+	if service.Environment, service.EnvPassthrough, err = loadEnvironment(fields["environment"]); err != nil {
+		return ServiceConfig{}, err
+	}
+	if service.Command, service.CommandShell, err = loadCommand("command", fields["command"]); err != nil {
+		return ServiceConfig{}, err
+	}
+	if service.Entrypoint, service.EntrypointShell, err = loadCommand("entrypoint", fields["entrypoint"]); err != nil {
+		return ServiceConfig{}, err
+	}
+
+	for key, target := range map[string]*[]string{
+		"profiles":       &service.Profiles,
+		"links":          &service.Links,
+		"external_links": &service.ExternalLinks,
+		"security_opt":   &service.SecurityOpt,
+		"dns_opt":        &service.DNSOpt,
+		"group_add":      &service.GroupAdd,
+		"volumes_from":   &service.VolumesFrom,
+	} {
+		if *target, err = loadStringList(key, fields[key]); err != nil {
+			return ServiceConfig{}, err
+		}
+	}
+	for key, target := range map[string]*string{
+		"pull_policy":   &service.PullPolicy,
+		"platform":      &service.Platform,
+		"cgroup_parent": &service.CgroupParent,
+		"cgroup":        &service.Cgroup,
+		"runtime":       &service.Runtime,
+		"isolation":     &service.Isolation,
+		"mac_address":   &service.MacAddress,
+	} {
+		if raw, ok := fields[key]; ok {
+			if *target, ok = scalarString(raw); !ok {
+				return ServiceConfig{}, fmt.Errorf("%s must be a scalar", key)
+			}
+		}
+	}
+	for key, target := range map[string]*bool{
+		"stdin_open": &service.StdinOpen,
+		"tty":        &service.TTY,
+		"read_only":  &service.ReadOnlyRootFS,
+	} {
+		if raw, ok := fields[key]; ok {
+			if *target, ok = raw.(bool); !ok {
+				return ServiceConfig{}, fmt.Errorf("%s must be a boolean", key)
+			}
+		}
+	}
+
+	if service.Extends, err = loadExtends(fields["extends"]); err != nil {
+		return ServiceConfig{}, err
+	}
+	if service.Build, err = loadBuild(fields["build"]); err != nil {
+		return ServiceConfig{}, err
+	}
+	if service.ExposedPorts, err = loadPorts(fields["ports"]); err != nil {
+		return ServiceConfig{}, err
+	}
+	if service.Volumes, err = loadVolumes(fields["volumes"]); err != nil {
+		return ServiceConfig{}, err
+	}
+	if service.DependsOn, service.Dependencies, err = loadDependsOn(fields["depends_on"]); err != nil {
+		return ServiceConfig{}, err
+	}
+
+	if err := checkUnsupportedKeys(fields); err != nil {
+		return ServiceConfig{}, err
+	}
+
+	return service, nil
+}
+
+// unsupportedServiceKeys are service keys ServiceConfig can hold, or that change how the
+// service runs, but that the loader doesn't read; loading them would silently drop them
+var unsupportedServiceKeys = map[string]bool{
+	"healthcheck":      true,
+	"deploy":           true,
+	"develop":          true,
+	"configs":          true,
+	"blkio_config":     true,
+	"env_file":         true,
+	"mem_limit":        true,
+	"memswap_limit":    true,
+	"mem_swappiness":   true,
+	"cpus":             true,
+	"cpu_shares":       true,
+	"cpuset":           true,
+	"oom_kill_disable": true,
+	"oom_score_adj":    true,
+}
+
+// checkUnsupportedKeys reports the first of unsupportedServiceKeys set on a service
+func checkUnsupportedKeys(fields map[string]interface{}) error {
+	var found []string
+	for key := range fields {
+		if unsupportedServiceKeys[key] {
+			found = append(found, key)
+		}
+	}
+	if len(found) == 0 {
+		return nil
+	}
+	sort.Strings(found)
+	return fmt.Errorf("%s is not supported when loading compose files", found[0])
+}
+
+// loadExtends reads extends, either a service name or a mapping naming one; extending a
+// service from another file isn't supported
+func loadExtends(raw interface{}) (string, error) {
+	switch value := raw.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return value, nil
+	case map[string]interface{}:
+		if _, ok := value["file"]; ok {
+			return "", fmt.Errorf("extends: file is not supported")
+		}
+		if service, ok := value["service"].(string); ok {
+			return service, nil
+		}
+	}
+	return "", fmt.Errorf("extends must name a service")
+}
+
+// loadBuild reads build, either a context path or a mapping
+func loadBuild(raw interface{}) (*BuildConfig, error) {
+	switch value := raw.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return &BuildConfig{Context: value}, nil
+	case map[string]interface{}:
+		build := &BuildConfig{}
+		for key, target := range map[string]*string{
+			"context":    &build.Context,
+			"dockerfile": &build.Dockerfile,
+			"target":     &build.Target,
+		} {
+			if entry, ok := value[key]; ok {
+				if *target, ok = scalarString(entry); !ok {
+					return nil, fmt.Errorf("build %s must be a scalar", key)
+				}
+			}
+		}
+		if build.Context == "" {
+			build.Context = "."
+		}
+
+		args, passthrough, err := loadEnvironment(value["args"])
+		if err != nil {
+			return nil, fmt.Errorf("build args: %w", err)
+		}
+		if len(passthrough) > 0 {
+			return nil, fmt.Errorf("build args: %s has no value", passthrough[0])
+		}
+		build.Args = args
+
+		if build.CacheFrom, err = loadStringList("build cache_from", value["cache_from"]); err != nil {
+			return nil, err
+		}
+		return build, nil
+	}
+	return nil, fmt.Errorf("build must be a path or a mapping")
+}
+
+// loadPorts reads ports in short ("8080:80/udp", "80", 80) and long form. Host IPs and
+// port ranges have no PortMapping equivalent and are an error.
+func loadPorts(raw interface{}) ([]PortMapping, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	items, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("ports must be a list")
+	}
+
+	var ports []PortMapping
+	for _, item := range items {
+		var port PortMapping
+		var err error
+		if long, ok := item.(map[string]interface{}); ok {
+			port, err = loadLongPort(long)
+		} else if spec, ok := scalarString(item); ok {
+			port, err = loadShortPort(spec)
+		} else {
+			err = fmt.Errorf("ports entries must be scalars or mappings, got %v", item)
+		}
+		if err != nil {
+			return nil, err
+		}
+		ports = append(ports, port)
+	}
+	return ports, nil
+}
+
+// loadShortPort parses "[host:]container[/protocol]"
+func loadShortPort(spec string) (PortMapping, error) {
+	var port PortMapping
+	mapping, protocol, _ := strings.Cut(spec, "/")
+	port.Protocol = protocol
+
+	parts := strings.Split(mapping, ":")
+	if len(parts) > 2 {
+		return PortMapping{}, fmt.Errorf("port %q: binding a host IP is not supported", spec)
+	}
+
+	var err error
+	if port.ContainerPort, err = strconv.Atoi(parts[len(parts)-1]); err != nil {
+		return PortMapping{}, fmt.Errorf("port %q: container port must be a number", spec)
+	}
+	if len(parts) == 2 && parts[0] != "" {
+		if port.HostPort, err = strconv.Atoi(parts[0]); err != nil {
+			return PortMapping{}, fmt.Errorf("port %q: host port must be a number", spec)
+		}
+	}
+	return port, nil
+}
+
+// loadLongPort reads a long-form port mapping
+func loadLongPort(fields map[string]interface{}) (PortMapping, error) {
+	if _, ok := fields["host_ip"]; ok {
+		return PortMapping{}, fmt.Errorf("port: host_ip is not supported")
+	}
+
+	var port PortMapping
+	target, ok := fields["target"].(int)
+	if !ok {
+		return PortMapping{}, fmt.Errorf("port: target must be a number")
+	}
+	port.ContainerPort = target
+
+	if published, ok := fields["published"]; ok {
+		s, _ := scalarString(published)
+		host, err := strconv.Atoi(s)
+		if err != nil {
+			return PortMapping{}, fmt.Errorf("port %d: published must be a number", target)
+		}
+		port.HostPort = host
+	}
+	if protocol, ok := fields["protocol"].(string); ok {
+		port.Protocol = protocol
+	}
+	return port, nil
+}
+
+// loadVolumes reads volumes in short ("source:target[:mode]") and long form. Anonymous
+// volumes, with only a target, have no VolumeMapping equivalent and are an error.
+func loadVolumes(raw interface{}) ([]VolumeMapping, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	items, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("volumes must be a list")
+	}
+
+	var volumes []VolumeMapping
+	for _, item := range items {
+		var volume VolumeMapping
+		var err error
+		switch value := item.(type) {
+		case string:
+			volume, err = loadShortVolume(value)
+		case map[string]interface{}:
+			volume, err = loadLongVolume(value)
+		default:
+			err = fmt.Errorf("volumes entries must be strings or mappings, got %v", item)
+		}
+		if err != nil {
+			return nil, err
+		}
+		volumes = append(volumes, volume)
+	}
+	return volumes, nil
+}
+
+// loadShortVolume parses "source:target[:mode]", where source may be a Windows drive path
+func loadShortVolume(spec string) (VolumeMapping, error) {
+	rest, drive := spec, ""
+	if isWindowsPath(spec) && !strings.HasPrefix(spec, `\`) {
+		drive, rest = spec[:2], spec[2:]
+	}
+
+	parts := strings.Split(rest, ":")
+	if len(parts) < 2 {
+		return VolumeMapping{}, fmt.Errorf("volume %q: anonymous volumes are not supported", spec)
+	}
+	if len(parts) > 3 {
+		return VolumeMapping{}, fmt.Errorf("volume %q: too many fields", spec)
+	}
+
+	volume := VolumeMapping{HostPath: drive + parts[0], ContainerPath: parts[1]}
+	if len(parts) == 3 {
+		for _, mode := range strings.Split(parts[2], ",") {
+			switch mode {
+			case "ro":
+				volume.ReadOnly = true
+			case "rw":
+			case "cached", "delegated", "consistent":
+				volume.Consistency = mode
+			default:
+				return VolumeMapping{}, fmt.Errorf("volume %q: mode %s is not supported", spec, mode)
+			}
+		}
+	}
+	return volume, nil
+}
+
+// loadLongVolume reads a long-form volume mapping
+func loadLongVolume(fields map[string]interface{}) (VolumeMapping, error) {
+	var volume VolumeMapping
+	volume.Type, _ = fields["type"].(string)
+	volume.HostPath, _ = fields["source"].(string)
+	volume.ContainerPath, _ = fields["target"].(string)
+	volume.ReadOnly, _ = fields["read_only"].(bool)
+	volume.Consistency, _ = fields["consistency"].(string)
+	if bind, ok := fields["bind"].(map[string]interface{}); ok {
+		volume.Propagation, _ = bind["propagation"].(string)
+	}
+	if named, ok := fields["volume"].(map[string]interface{}); ok {
+		volume.NoCopy, _ = named["nocopy"].(bool)
+	}
+
+	if volume.ContainerPath == "" {
+		return VolumeMapping{}, fmt.Errorf("volume: target is required")
+	}
+	if volume.HostPath == "" && volume.Type != "tmpfs" {
+		return VolumeMapping{}, fmt.Errorf("volume %s: anonymous volumes are not supported", volume.ContainerPath)
+	}
+	return volume, nil
+}
+
+// loadDependsOn reads depends_on, either a list of service names or a mapping of
+// long-form entries keyed by service name
+func loadDependsOn(raw interface{}) ([]string, map[string]Dependency, error) {
+	mapping, ok := raw.(map[string]interface{})
+	if !ok {
+		list, err := loadStringList("depends_on", raw)
+		if err != nil {
+			return nil, nil, fmt.Errorf("depends_on must be a list or a mapping")
+		}
+		return list, nil, nil
+	}
+
+	dependencies := make(map[string]Dependency)
+	for name, entry := range mapping {
+		fields, ok := entry.(map[string]interface{})
+		if !ok && entry != nil {
+			return nil, nil, fmt.Errorf("depends_on %s: must be a mapping", name)
+		}
+		if _, ok := fields["required"]; ok {
+			return nil, nil, fmt.Errorf("depends_on %s: required is not supported", name)
+		}
+
+		var dependency Dependency
+		dependency.Condition, _ = fields["condition"].(string)
+		dependency.Restart, _ = fields["restart"].(bool)
+		dependencies[name] = dependency
+	}
+	return nil, dependencies, nil
+}
+
+// loadEnvironment normalizes both environment forms, a KEY: value mapping or a list of
+// KEY=value entries, into a map. Entries without a value, "KEY" in list form or "KEY:"
+// in map form, pass the variable through from the host and are returned separately.
+func loadEnvironment(raw interface{}) (map[string]string, []string, error) {
+	if raw == nil {
+		return nil, nil, nil
+	}
+
+	env := make(map[string]string)
+	var passthrough []string
+	switch value := raw.(type) {
+	case map[string]interface{}:
+		for key, entry := range value {
+			if entry == nil {
+				passthrough = append(passthrough, key)
+				continue
+			}
+			s, ok := scalarString(entry)
+			if !ok {
+				return nil, nil, fmt.Errorf("environment %s: value must be a scalar", key)
+			}
+			env[key] = s
+		}
+	case []interface{}:
+		for _, entry := range value {
+			s, ok := entry.(string)
+			if !ok {
+				return nil, nil, fmt.Errorf("environment entries must be strings, got %v", entry)
+			}
+			if key, val, found := strings.Cut(s, "="); found {
+				env[key] = val
+			} else {
+				passthrough = append(passthrough, s)
+			}
+		}
+	default:
+		return nil, nil, fmt.Errorf("environment must be a mapping or a list")
+	}
+
+	sort.Strings(passthrough)
+	if len(env) == 0 {
+		env = nil
+	}
+	return env, passthrough, nil
+}
+
+// loadCommand reads command or entrypoint: the list form as arguments, and the string
+// form as the shell form, kept as written for compose to interpret
+func loadCommand(key string, raw interface{}) ([]string, string, error) {
+	if s, ok := raw.(string); ok {
+		return nil, s, nil
+	}
+	list, err := loadStringList(key, raw)
+	if err == nil && list == nil && raw != nil {
+		list = []string{}
+	}
+	return list, "", err
+}
+
+// loadStringList reads a list of scalars
+func loadStringList(key string, raw interface{}) ([]string, error) {
+	if raw == nil {
+		return nil, nil
+	}
+
+	items, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s must be a list", key)
+	}
+	var list []string
+	for _, item := range items {
+		s, ok := scalarString(item)
+		if !ok {
+			return nil, fmt.Errorf("%s entries must be scalars, got %v", key, item)
+		}
+		list = append(list, s)
+	}
+	return list, nil
+}
+
+// scalarString renders a parsed YAML scalar as the string compose would use
+func scalarString(raw interface{}) (string, bool) {
+	switch value := raw.(type) {
+	case string:
+		return value, true
+	case int:
+		return strconv.Itoa(value), true
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(value), true
+	}
+	return "", false
+}
+
+// splitImageRef splits "name:tag" into its parts. References pinned by digest are kept whole.
+func splitImageRef(image string) (string, string) {
+	if strings.Contains(image, "@") {
+		return image, ""
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image[:i], image[i+1:]
+	}
+	return image, ""
+}
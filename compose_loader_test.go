@@ -0,0 +1,365 @@
+package thirdpartyhosting
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeComposeFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "docker-compose.yml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestLoadComposeConfigEnvironmentForms(t *testing.T) {
+	mapForm := writeComposeFile(t, `
+name: fider
+services:
+  app:
+    image: getfider/fider:stable
+    environment:
+      BASE_URL: http://localhost
+      EMAIL_SMTP_PORT: 587
+      DEBUG: "true"
+      EMPTY: ""
+      SECRET_KEY:
+`)
+	listForm := writeComposeFile(t, `
+name: fider
+services:
+  app:
+    image: getfider/fider:stable
+    environment:
+      - BASE_URL=http://localhost
+      - EMAIL_SMTP_PORT=587
+      - DEBUG=true
+      - EMPTY=
+      - SECRET_KEY
+`)
+
+	fromMap, err := LoadComposeConfig(mapForm)
+	require.NoError(t, err)
+	fromList, err := LoadComposeConfig(listForm)
+	require.NoError(t, err)
+
+	expected := map[string]string{
+		"BASE_URL":        "http://localhost",
+		"EMAIL_SMTP_PORT": "587",
+		"DEBUG":           "true",
+		"EMPTY":           "",
+	}
+	assert.Equal(t, expected, fromMap.Services["app"].Environment)
+	assert.Equal(t, []string{"SECRET_KEY"}, fromMap.Services["app"].EnvPassthrough)
+
+	// Only BaseDir differs, since each file lives in its own temp directory
+	fromList.BaseDir = fromMap.BaseDir
+	assert.Equal(t, fromMap, fromList)
+}
+
+func TestLoadComposeConfigValueInListEntryKeepsEquals(t *testing.T) {
+	path := writeComposeFile(t, `
+services:
+  app:
+    image: app
+    environment:
+      - DSN=postgres://db?sslmode=disable
+`)
+
+	config, err := LoadComposeConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, "postgres://db?sslmode=disable", config.Services["app"].Environment["DSN"])
+}
+
+func TestLoadComposeConfigServiceFields(t *testing.T) {
+	path := writeComposeFile(t, `
+version: "3.8"
+services:
+  app:
+    image: registry.example.com:5000/app:1.2
+    command: sh -c 'echo "hello world"'
+    entrypoint: []
+    restart: always
+    profiles: [debug]
+  db:
+    image: postgres@sha256:abc
+`)
+
+	config, err := LoadComposeConfig(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, filepath.Dir(path), config.BaseDir)
+	assert.Equal(t, "3.8", config.ComposeVersion)
+
+	app := config.Services["app"]
+	assert.Equal(t, "registry.example.com:5000/app", app.ImageName)
+	assert.Equal(t, "1.2", app.ImageTag)
+	assert.Nil(t, app.Command)
+	assert.Equal(t, `sh -c 'echo "hello world"'`, app.CommandShell)
+	assert.Equal(t, []string{}, app.Entrypoint)
+	assert.Empty(t, app.EntrypointShell)
+	assert.Equal(t, "always", app.RestartPolicy)
+	assert.Equal(t, []string{"debug"}, app.Profiles)
+
+	assert.Equal(t, "postgres@sha256:abc", config.Services["db"].ImageName)
+	assert.Empty(t, config.Services["db"].ImageTag)
+}
+
+func TestLoadComposeConfigRoundTrip(t *testing.T) {
+	path := writeComposeFile(t, `
+name: fider
+services:
+  app:
+    build:
+      context: ./app
+      dockerfile: Dockerfile.prod
+      args:
+        - NODE_ENV=production
+      target: production
+    image: getfider/fider:stable
+    ports:
+      - "3000:3000"
+      - 9229
+      - target: 53
+        published: "5353"
+        protocol: udp
+    volumes:
+      - ./uploads:/app/uploads
+      - ./config.yml:/app/config.yml:ro
+      - type: bind
+        source: ./shared
+        target: /shared
+        bind:
+          propagation: rshared
+    depends_on:
+      db:
+        condition: service_healthy
+        restart: true
+      cache:
+    tty: true
+  db:
+    image: postgres:13
+    volumes:
+      - pg_data:/var/lib/postgresql/data
+  cache:
+    image: redis:7
+  worker:
+    build: ./worker
+    depends_on: [db, cache]
+    read_only: true
+`)
+
+	config, err := LoadComposeConfig(path)
+	require.NoError(t, err)
+
+	app := config.Services["app"]
+	assert.Equal(t, &BuildConfig{Context: "./app", Dockerfile: "Dockerfile.prod",
+		Args: map[string]string{"NODE_ENV": "production"}, Target: "production"}, app.Build)
+	assert.Equal(t, []PortMapping{
+		{HostPort: 3000, ContainerPort: 3000},
+		{ContainerPort: 9229},
+		{HostPort: 5353, ContainerPort: 53, Protocol: "udp"},
+	}, app.ExposedPorts)
+	assert.Equal(t, []VolumeMapping{
+		{HostPath: "./uploads", ContainerPath: "/app/uploads"},
+		{HostPath: "./config.yml", ContainerPath: "/app/config.yml", ReadOnly: true},
+		{HostPath: "./shared", ContainerPath: "/shared", Type: "bind", Propagation: "rshared"},
+	}, app.Volumes)
+	assert.Equal(t, map[string]Dependency{
+		"db":    {Condition: "service_healthy", Restart: true},
+		"cache": {},
+	}, app.Dependencies)
+	assert.True(t, app.TTY)
+
+	worker := config.Services["worker"]
+	assert.Equal(t, &BuildConfig{Context: "./worker"}, worker.Build)
+	assert.Equal(t, []string{"db", "cache"}, worker.DependsOn)
+	assert.True(t, worker.ReadOnlyRootFS)
+
+	// Loading what the loaded config renders to renders the same file again
+	content, err := generateComposeContent(config)
+	require.NoError(t, err)
+	reloaded, err := LoadComposeConfig(writeComposeFile(t, content))
+	require.NoError(t, err)
+	rerendered, err := generateComposeContent(reloaded)
+	require.NoError(t, err)
+	assert.Equal(t, content, rerendered)
+}
+
+func TestLoadComposeConfigRejectsUnsupportedKeys(t *testing.T) {
+	tests := map[string]string{
+		"healthcheck":       "    healthcheck:\n      test: [CMD, true]\n",
+		"deploy":            "    deploy:\n      replicas: 2\n",
+		"mem_limit":         "    mem_limit: 512m\n",
+		"binding a host IP": "    ports:\n      - 127.0.0.1:8080:80\n",
+		"anonymous volumes": "    volumes:\n      - /data\n",
+	}
+	for name, fields := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := writeComposeFile(t, "services:\n  app:\n    image: app\n"+fields)
+
+			_, err := LoadComposeConfig(path)
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "service app")
+			assert.Contains(t, err.Error(), name)
+		})
+	}
+}
+
+func TestLoadComposeConfigRejectsInvalidEnvironment(t *testing.T) {
+	path := writeComposeFile(t, `
+services:
+  app:
+    image: app
+    environment: FOO=bar
+`)
+
+	_, err := LoadComposeConfig(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "service app")
+}
+
+func TestLoadComposeConfigMissingFile(t *testing.T) {
+	_, err := LoadComposeConfig(filepath.Join(t.TempDir(), "missing.yml"))
+	assert.Error(t, err)
+}
+
+func TestLoadComposeConfigMergesOverrideFiles(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "docker-compose.yml")
+	override := filepath.Join(dir, "docker-compose.override.yml")
+	require.NoError(t, os.WriteFile(base, []byte(`
+name: fider
+services:
+  app:
+    image: getfider/fider:stable
+    restart: always
+    environment:
+      LOG_LEVEL: info
+      BASE_URL: http://localhost
+  db:
+    image: postgres:13
+`), 0644))
+	require.NoError(t, os.WriteFile(override, []byte(`
+services:
+  app:
+    image: getfider/fider:main
+    environment:
+      - LOG_LEVEL=debug
+  mailhog:
+    image: mailhog/mailhog
+`), 0644))
+
+	config, err := LoadComposeConfig(base, override)
+
+	require.NoError(t, err)
+	assert.Equal(t, "fider", config.ProjectName)
+	assert.Equal(t, dir, config.BaseDir)
+	assert.ElementsMatch(t, []string{"app", "db", "mailhog"}, serviceNames(config))
+
+	app := config.Services["app"]
+	assert.Equal(t, "main", app.ImageTag)
+	assert.Equal(t, "always", app.RestartPolicy)
+	assert.Equal(t, map[string]string{"LOG_LEVEL": "debug", "BASE_URL": "http://localhost"}, app.Environment)
+}
+
+func TestLoadComposeConfigOverridesPortsAndVolumes(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "docker-compose.yml")
+	override := filepath.Join(dir, "docker-compose.override.yml")
+	require.NoError(t, os.WriteFile(base, []byte(`
+services:
+  app:
+    image: getfider/fider:stable
+    ports:
+      - "3000:3000"
+    volumes:
+      - ./uploads:/app/uploads
+      - pg_data:/var/lib/postgresql/data
+`), 0644))
+	require.NoError(t, os.WriteFile(override, []byte(`
+services:
+  app:
+    ports:
+      - "8080:3000"
+    volumes:
+      - ./dev-uploads:/app/uploads:ro
+`), 0644))
+
+	config, err := LoadComposeConfig(base, override)
+
+	require.NoError(t, err)
+	app := config.Services["app"]
+	// Ports are added to base's, as compose merges them, while a volume replaces the
+	// one base mounts at the same container path
+	assert.Equal(t, []PortMapping{
+		{HostPort: 3000, ContainerPort: 3000},
+		{HostPort: 8080, ContainerPort: 3000},
+	}, app.ExposedPorts)
+	assert.Equal(t, []VolumeMapping{
+		{HostPath: "./dev-uploads", ContainerPath: "/app/uploads", ReadOnly: true},
+		{HostPath: "pg_data", ContainerPath: "/var/lib/postgresql/data"},
+	}, app.Volumes)
+}
+
+func TestLoadComposeConfigNamesFailingFile(t *testing.T) {
+	base := writeComposeFile(t, "services:\n  app:\n    image: app\n")
+	override := writeComposeFile(t, "services:\n  app:\n    environment: 42\n")
+
+	_, err := LoadComposeConfig(base, override)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "compose file "+override)
+	assert.Contains(t, err.Error(), "service app")
+}
+
+func TestLoadComposeConfigValidatesEachFile(t *testing.T) {
+	base := writeComposeFile(t, "services:\n  app:\n    image: app\n")
+	override := writeComposeFile(t, "services:\n  app:\n    external_links: [\"redis:cache:extra\"]\n")
+
+	_, err := LoadComposeConfig(base, override)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "compose file "+override+": service app: invalid external link")
+	assert.NotContains(t, err.Error(), "merged")
+
+	// A file that only makes sense merged, like an override without the image, is accepted
+	override = writeComposeFile(t, "services:\n  app:\n    links: [db]\n  db:\n    restart: always\n")
+	extra := writeComposeFile(t, "services:\n  db:\n    image: postgres:13\n")
+
+	config, err := LoadComposeConfig(base, override, extra)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"db"}, config.Services["app"].Links)
+	assert.Equal(t, "always", config.Services["db"].RestartPolicy)
+}
+
+func TestLoadComposeConfigValidatesMergedResult(t *testing.T) {
+	base := writeComposeFile(t, "services:\n  app:\n    image: app\n")
+	override := writeComposeFile(t, "services:\n  worker:\n    restart: always\n")
+
+	_, err := LoadComposeConfig(base, override)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "merged")
+	assert.Contains(t, err.Error(), "service worker")
+}
+
+func TestLoadComposeConfigNoPaths(t *testing.T) {
+	_, err := LoadComposeConfig()
+	assert.Error(t, err)
+}
+
+// serviceNames returns the names of the services in config
+func serviceNames(config ComposeConfig) []string {
+	var names []string
+	for name := range config.Services {
+		names = append(names, name)
+	}
+	return names
+}
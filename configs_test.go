@@ -0,0 +1,76 @@
+package thirdpartyhosting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateComposeContentInlineConfig(t *testing.T) {
+	config := ComposeConfig{
+		BaseDir: "/srv/stack",
+		Configs: map[string]ConfigFile{
+			"nginx_conf": {Content: "server {\n  listen 80;\n\n  location / {\n    proxy_pass http://app:3000;\n  }\n}\n"},
+			"motd":       {Content: "welcome"},
+			"app_yaml":   {File: "./config/app.yaml"},
+		},
+		Services: map[string]ServiceConfig{
+			"proxy": {
+				ImageName: "nginx",
+				Configs: []ConfigMount{
+					{Source: "nginx_conf", Target: "/etc/nginx/conf.d/default.conf"},
+					{Source: "motd"},
+				},
+			},
+		},
+	}
+
+	content, err := generateComposeContent(config)
+
+	assert.NoError(t, err)
+	assert.NoError(t, ValidateConfig(config))
+	assert.Contains(t, content, "    configs:\n"+
+		"      - source: nginx_conf\n"+
+		"        target: /etc/nginx/conf.d/default.conf\n"+
+		"      - motd\n")
+	assert.Contains(t, content, "\nconfigs:\n"+
+		"  app_yaml:\n"+
+		"    file: /srv/stack/config/app.yaml\n"+
+		"  motd:\n"+
+		"    content: |-\n"+
+		"      welcome\n"+
+		"  nginx_conf:\n"+
+		"    content: |\n"+
+		"      server {\n"+
+		"        listen 80;\n"+
+		"\n"+
+		"        location / {\n"+
+		"          proxy_pass http://app:3000;\n"+
+		"        }\n"+
+		"      }\n")
+}
+
+func TestValidateConfigConfigs(t *testing.T) {
+	both := ComposeConfig{
+		Configs:  map[string]ConfigFile{"app": {File: "app.yaml", Content: "x"}},
+		Services: map[string]ServiceConfig{"app": {ImageName: "app"}},
+	}
+	assert.Error(t, ValidateConfig(both))
+
+	undefined := ComposeConfig{
+		Services: map[string]ServiceConfig{"app": {ImageName: "app", Configs: []ConfigMount{{Source: "missing"}}}},
+	}
+	err := ValidateConfig(undefined)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "config missing is not defined")
+}
+
+func TestYAMLBlockChomping(t *testing.T) {
+	doc := yamlMap().
+		set("clip", yamlBlock("a\n")).
+		set("strip", yamlBlock("a")).
+		set("keep", yamlBlock("a\n\n")).
+		set("indented", yamlBlock("  a\n"))
+
+	assert.Equal(t, "clip: |\n  a\n\nstrip: |-\n  a\n\nkeep: \"a\\n\\n\"\n\nindented: |2\n    a\n", marshalYAML(doc, RenderOptions{}))
+}
@@ -0,0 +1,54 @@
+package thirdpartyhosting
+
+import "sort"
+
+// dependencyConditions lists the long-form depends_on conditions compose accepts
+var dependencyConditions = map[string]bool{
+	"service_started":                true,
+	"service_healthy":                true,
+	"service_completed_successfully": true,
+}
+
+// serviceDependencies returns the names of every service a service depends on,
+// from both DependsOn and Dependencies, sorted and without duplicates
+func serviceDependencies(service ServiceConfig) []string {
+	seen := make(map[string]bool)
+	var deps []string
+	for _, dep := range service.DependsOn {
+		if !seen[dep] {
+			seen[dep] = true
+			deps = append(deps, dep)
+		}
+	}
+	for dep := range service.Dependencies {
+		if !seen[dep] {
+			seen[dep] = true
+			deps = append(deps, dep)
+		}
+	}
+
+	sort.Strings(deps)
+	return deps
+}
+
+// composeDependencies builds the long-form depends_on entries for a service, or nil
+// when the short list form is enough. DependsOn entries get the service_started condition.
+func composeDependencies(service ServiceConfig) map[string]ComposeDependency {
+	if len(service.Dependencies) == 0 {
+		return nil
+	}
+
+	deps := make(map[string]ComposeDependency)
+	for _, dep := range service.DependsOn {
+		deps[dep] = ComposeDependency{Condition: "service_started"}
+	}
+	for name, dep := range service.Dependencies {
+		condition := dep.Condition
+		if condition == "" {
+			condition = "service_started"
+		}
+		deps[name] = ComposeDependency{Condition: condition, Restart: dep.Restart}
+	}
+
+	return deps
+}
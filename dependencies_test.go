@@ -0,0 +1,67 @@
+package thirdpartyhosting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateComposeContentDependencyRestart(t *testing.T) {
+	config := ComposeConfig{
+		Services: map[string]ServiceConfig{
+			"app": {
+				ImageName: "app",
+				DependsOn: []string{"cache"},
+				Dependencies: map[string]Dependency{
+					"db": {Condition: "service_healthy", Restart: true},
+				},
+			},
+			"cache": {ImageName: "redis"},
+			"db":    {ImageName: "postgres"},
+		},
+	}
+
+	content, err := generateComposeContent(config)
+
+	assert.NoError(t, err)
+	assert.Contains(t, content, "    depends_on:\n"+
+		"      cache:\n"+
+		"        condition: service_started\n"+
+		"      db:\n"+
+		"        condition: service_healthy\n"+
+		"        restart: true\n")
+}
+
+func TestGenerateComposeContentShortDependsOn(t *testing.T) {
+	content, err := generateComposeContent(testConfig())
+
+	assert.NoError(t, err)
+	assert.Contains(t, content, "    depends_on:\n      - db\n")
+}
+
+func TestStartOrderUsesDependencies(t *testing.T) {
+	config := threeTierConfig()
+	web := config.Services["web"]
+	web.DependsOn = nil
+	web.Dependencies = map[string]Dependency{"api": {Restart: true}}
+	config.Services["web"] = web
+
+	order, err := startOrder(config)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"db", "api", "web"}, order)
+}
+
+func TestValidateConfigDependencyCondition(t *testing.T) {
+	config := ComposeConfig{
+		Services: map[string]ServiceConfig{
+			"app": {ImageName: "app", Dependencies: map[string]Dependency{"db": {Condition: "service_ready"}}},
+			"db":  {ImageName: "postgres"},
+		},
+	}
+
+	err := ValidateConfig(config)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "service_ready")
+}
@@ -0,0 +1,127 @@
+package thirdpartyhosting
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// localRestartPolicies maps deploy.restart_policy conditions to the restart values docker
+// uses outside swarm mode; swarm's default condition is "any"
+var localRestartPolicies = map[string]string{
+	"":           "always",
+	"any":        "always",
+	"on-failure": "on-failure",
+	"none":       "no",
+}
+
+// localDeploy translates the swarm-only deploy settings of config for a local docker-compose
+// up: deploy.restart_policy becomes restart and deploy.replicas becomes a --scale argument.
+// It returns a translated copy of config, the --scale arguments for the given services (all
+// of them when empty), and warnings for settings that have no local equivalent.
+func localDeploy(config ComposeConfig, services []string) (ComposeConfig, []string, []string) {
+	config = config.Clone()
+	scaled := make(map[string]bool)
+	for _, service := range services {
+		scaled[service] = true
+	}
+
+	names := make([]string, 0, len(config.Services))
+	for name := range config.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var scale, warnings []string
+	for _, name := range names {
+		service := config.Services[name]
+		if service.Deploy == nil {
+			continue
+		}
+		deploy := *service.Deploy
+
+		if replicas := deploy.Replicas; replicas != nil {
+			if len(services) == 0 || scaled[name] {
+				scale = append(scale, "--scale", name+"="+strconv.Itoa(*replicas))
+			}
+			deploy.Replicas = nil
+		}
+
+		if policy := deploy.RestartPolicy; policy != nil {
+			service.RestartPolicy = localRestartPolicies[policy.Condition]
+			if policy.MaxAttempts > 0 {
+				if policy.Condition == "on-failure" {
+					service.RestartPolicy += ":" + strconv.Itoa(policy.MaxAttempts)
+				} else {
+					warnings = append(warnings, fmt.Sprintf("service %s: deploy.restart_policy max_attempts only applies to on-failure outside swarm mode and is ignored", name))
+				}
+			}
+			if policy.Delay > 0 || policy.Window > 0 {
+				warnings = append(warnings, fmt.Sprintf("service %s: deploy.restart_policy delay and window have no equivalent outside swarm mode and are ignored", name))
+			}
+			deploy.RestartPolicy = nil
+		}
+
+		service.Deploy = &deploy
+		config.Services[name] = service
+	}
+
+	return config, scale, warnings
+}
+
+// renderComposeFile writes config to the provider's compose file as every docker-compose
+// command sees it: in the compose file version detected by Validate unless ComposeVersion
+// is set, and, unless SwarmMode is set, translated by localDeploy. It also returns the
+// --scale arguments for the given services (all of them when empty).
+func (p *DockerComposeProvider) renderComposeFile(config ComposeConfig, services []string) (string, []string, error) {
+	config, scale := p.deployedConfig(config, services)
+
+	composeFile, err := generateComposeFile(config, p.ComposeFileName)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate compose file: %w", err)
+	}
+	return composeFile, scale, nil
+}
+
+// deployedConfig returns config as renderComposeFile writes it, along with the --scale
+// arguments for the given services (all of them when empty)
+func (p *DockerComposeProvider) deployedConfig(config ComposeConfig, services []string) (ComposeConfig, []string) {
+	config = p.withDetectedComposeVersion(config)
+
+	var scale []string
+	if !p.SwarmMode {
+		config, scale, _ = localDeploy(config, services)
+	}
+	return config, scale
+}
+
+// Warnings returns ConfigWarnings for the initialized configuration, plus, unless
+// SwarmMode is set, the deploy settings Start cannot translate for local docker-compose
+func (p *DockerComposeProvider) Warnings() ([]string, error) {
+	p.mu.RLock()
+	if !p.initialized {
+		p.mu.RUnlock()
+		return nil, fmt.Errorf("provider not initialized")
+	}
+	config := p.config
+	p.mu.RUnlock()
+
+	warnings := ConfigWarnings(config)
+	if !p.SwarmMode {
+		_, _, local := localDeploy(config, nil)
+		warnings = append(warnings, local...)
+		sort.Strings(warnings)
+	}
+	return warnings, nil
+}
+
+// upServices returns the services named in docker-compose up arguments, skipping flags
+func upServices(args []string) []string {
+	var services []string
+	for _, arg := range args {
+		if len(arg) > 0 && arg[0] != '-' {
+			services = append(services, arg)
+		}
+	}
+	return services
+}
@@ -0,0 +1,143 @@
+package thirdpartyhosting
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// deployModeConfig has a replicated worker with a swarm restart policy
+func deployModeConfig() ComposeConfig {
+	replicas := 3
+	return ComposeConfig{Services: map[string]ServiceConfig{
+		"worker": {ImageName: "worker", Deploy: &DeployConfig{
+			Replicas:      &replicas,
+			RestartPolicy: &DeployRestartPolicy{Condition: "on-failure", MaxAttempts: 5},
+		}},
+		"web": {ImageName: "web"},
+	}}
+}
+
+// captureUp records the up command and the compose file it was given
+func captureUp(t *testing.T, command *string, composeFile *string) func(ctx context.Context, call string) ([]byte, error) {
+	return func(ctx context.Context, call string) ([]byte, error) {
+		if strings.Contains(call, " up -d") {
+			*command = call
+			fields := strings.Fields(call)
+			for i, field := range fields {
+				if field == "-f" {
+					content, err := os.ReadFile(fields[i+1])
+					require.NoError(t, err)
+					*composeFile = string(content)
+				}
+			}
+		}
+		return nil, nil
+	}
+}
+
+func TestLocalModeTranslatesReplicasToScale(t *testing.T) {
+	var command, composeFile string
+	provider, _ := newTestProvider(t, deployModeConfig(), captureUp(t, &command, &composeFile))
+
+	require.NoError(t, provider.Start(context.Background()))
+
+	assert.True(t, strings.HasSuffix(command, " up -d --scale worker=3"), command)
+	assert.Contains(t, composeFile, "  worker:\n    image: worker\n    restart: on-failure:5\n")
+	assert.NotContains(t, composeFile, "deploy:")
+}
+
+func TestSwarmModeKeepsDeploy(t *testing.T) {
+	var command, composeFile string
+	provider, _ := newTestProvider(t, deployModeConfig(), captureUp(t, &command, &composeFile))
+	provider.SwarmMode = true
+
+	require.NoError(t, provider.Start(context.Background()))
+
+	assert.NotContains(t, command, "--scale")
+	assert.Contains(t, composeFile, "    deploy:\n      replicas: 3\n      restart_policy:\n        condition: on-failure\n        max_attempts: 5\n")
+	assert.NotContains(t, composeFile, "restart:")
+}
+
+func TestLocalModeScalesOnlyNamedServices(t *testing.T) {
+	_, scale, _ := localDeploy(deployModeConfig(), []string{"web"})
+	assert.Empty(t, scale)
+
+	_, scale, _ = localDeploy(deployModeConfig(), []string{"worker"})
+	assert.Equal(t, []string{"--scale", "worker=3"}, scale)
+}
+
+func TestLocalDeployRestartConditions(t *testing.T) {
+	for condition, expected := range map[string]string{"": "always", "any": "always", "on-failure": "on-failure", "none": "no"} {
+		config := ComposeConfig{Services: map[string]ServiceConfig{
+			"app": {ImageName: "app", Deploy: &DeployConfig{RestartPolicy: &DeployRestartPolicy{Condition: condition}}},
+		}}
+
+		local, _, warnings := localDeploy(config, nil)
+
+		assert.Equal(t, expected, local.Services["app"].RestartPolicy, condition)
+		assert.Nil(t, local.Services["app"].Deploy.RestartPolicy, condition)
+		assert.Empty(t, warnings, condition)
+		assert.NotNil(t, config.Services["app"].Deploy.RestartPolicy, "the original config must be left alone")
+	}
+}
+
+func TestWarningsForUntranslatableDeploySettings(t *testing.T) {
+	config := ComposeConfig{Services: map[string]ServiceConfig{
+		"app": {ImageName: "app", Deploy: &DeployConfig{RestartPolicy: &DeployRestartPolicy{
+			Condition: "any", MaxAttempts: 3, Delay: 5 * time.Second,
+		}}},
+	}}
+	provider, _ := newTestProvider(t, config, nil)
+
+	warnings, err := provider.Warnings()
+	require.NoError(t, err)
+	assert.Len(t, warnings, 2)
+	assert.Contains(t, warnings[0], "delay and window")
+	assert.Contains(t, warnings[1], "max_attempts")
+
+	provider.SwarmMode = true
+	warnings, err = provider.Warnings()
+	require.NoError(t, err)
+	assert.Empty(t, warnings)
+}
+
+func TestScaledServiceTracksEveryContainer(t *testing.T) {
+	states := map[string]string{"worker-1": "running", "worker-2": "running", "worker-3": "running", "web-1": "running"}
+	provider, runner := newTestProvider(t, deployModeConfig(), func(ctx context.Context, command string) ([]byte, error) {
+		switch {
+		case strings.HasSuffix(command, "ps -q worker"):
+			return []byte("worker-1\nworker-2\nworker-3\n"), nil
+		case strings.HasSuffix(command, "ps -q web"):
+			return []byte("web-1\n"), nil
+		case strings.HasPrefix(command, "docker inspect --type container "):
+			var entries []string
+			for _, id := range strings.Fields(strings.TrimPrefix(command, "docker inspect --type container ")) {
+				entries = append(entries, inspectEntry(id, states[id]))
+			}
+			return []byte("[" + strings.Join(entries, ",") + "]\n"), nil
+		}
+		return nil, nil
+	})
+	require.NoError(t, provider.Start(context.Background()))
+
+	assert.Equal(t, "worker-1", provider.GetContainerID("worker"))
+	assert.Equal(t, []string{"worker-1", "worker-2", "worker-3"}, provider.GetContainerIDs("worker"))
+
+	statuses, err := provider.Status(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"worker": "running", "web": "running"}, statuses)
+
+	states["worker-2"] = "exited"
+	statuses, err = provider.Status(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "exited", statuses["worker"])
+
+	require.NoError(t, provider.RemoveService(context.Background(), "worker", true))
+	assert.NotEmpty(t, runner.CallsContaining("docker rm -f worker-1 worker-2 worker-3"))
+}
@@ -0,0 +1,52 @@
+package thirdpartyhosting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateComposeContentDevelopWatch(t *testing.T) {
+	config := ComposeConfig{
+		BaseDir: "/srv/stack",
+		Services: map[string]ServiceConfig{
+			"web": {
+				Build: &BuildConfig{Context: "./web"},
+				Develop: &DevelopConfig{Watch: []WatchRule{
+					{Path: "./web/src", Action: "sync", Target: "/app/src", Ignore: []string{"node_modules/"}},
+					{Path: "./web/package.json", Action: "rebuild"},
+				}},
+			},
+		},
+	}
+
+	content, err := generateComposeContent(config)
+
+	assert.NoError(t, err)
+	assert.NoError(t, ValidateConfig(config))
+	assert.Contains(t, content, "    develop:\n"+
+		"      watch:\n"+
+		"        - path: /srv/stack/web/src\n"+
+		"          action: sync\n"+
+		"          target: /app/src\n"+
+		"          ignore:\n"+
+		"            - node_modules/\n"+
+		"        - path: /srv/stack/web/package.json\n"+
+		"          action: rebuild\n")
+}
+
+func TestValidateConfigWatchRules(t *testing.T) {
+	for _, rule := range []WatchRule{
+		{Path: "./src", Action: "copy", Target: "/app"},
+		{Path: "./src", Action: "sync"},
+		{Action: "rebuild"},
+	} {
+		config := ComposeConfig{
+			Services: map[string]ServiceConfig{
+				"web": {ImageName: "web", Develop: &DevelopConfig{Watch: []WatchRule{rule}}},
+			},
+		}
+
+		assert.Error(t, ValidateConfig(config), "rule %+v", rule)
+	}
+}
@@ -0,0 +1,140 @@
+package thirdpartyhosting
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Kinds of ServiceDiff
+const (
+	ServiceAdded   = "added"
+	ServiceRemoved = "removed"
+	ServiceChanged = "changed"
+)
+
+// ServiceDiff describes how one service differs between two configs
+type ServiceDiff struct {
+	Service string
+	Change  string      // ServiceAdded, ServiceRemoved or ServiceChanged
+	Fields  []FieldDiff // the fields that differ, for ServiceChanged
+}
+
+// FieldDiff is a single changed ServiceConfig field. Environment variables are reported
+// individually as "Environment.KEY"; a nil Old or New means the variable was added or removed.
+type FieldDiff struct {
+	Field string
+	Old   interface{}
+	New   interface{}
+}
+
+// Diff compares the initialized config with newConfig service by service, like a plan
+// of what applying newConfig would change. Services are returned sorted by name and
+// unchanged services are left out. newConfig must pass ValidateConfig.
+func (p *DockerComposeProvider) Diff(newConfig ComposeConfig) ([]ServiceDiff, error) {
+	p.mu.RLock()
+	if !p.initialized {
+		p.mu.RUnlock()
+		return nil, fmt.Errorf("provider not initialized")
+	}
+	config := p.config
+	p.mu.RUnlock()
+
+	if err := ValidateConfig(newConfig); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	// The initialized config is stored with extends resolved, so newConfig is compared the same way
+	newConfig, err := resolveExtends(newConfig)
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	names := make(map[string]bool)
+	for name := range config.Services {
+		names[name] = true
+	}
+	for name := range newConfig.Services {
+		names[name] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	var diffs []ServiceDiff
+	for _, name := range sorted {
+		oldService, inOld := config.Services[name]
+		newService, inNew := newConfig.Services[name]
+
+		switch {
+		case !inOld:
+			diffs = append(diffs, ServiceDiff{Service: name, Change: ServiceAdded})
+		case !inNew:
+			diffs = append(diffs, ServiceDiff{Service: name, Change: ServiceRemoved})
+		default:
+			if fields := diffServiceFields(oldService, newService); len(fields) > 0 {
+				diffs = append(diffs, ServiceDiff{Service: name, Change: ServiceChanged, Fields: fields})
+			}
+		}
+	}
+
+	return diffs, nil
+}
+
+// diffServiceFields compares two service configs field by field, in declaration order
+func diffServiceFields(oldService, newService ServiceConfig) []FieldDiff {
+	var diffs []FieldDiff
+
+	oldValue, newValue := reflect.ValueOf(oldService), reflect.ValueOf(newService)
+	for i := 0; i < oldValue.NumField(); i++ {
+		field := oldValue.Type().Field(i).Name
+		if field == "Environment" {
+			diffs = append(diffs, diffEnvironment(oldService.Environment, newService.Environment)...)
+			continue
+		}
+
+		before, after := oldValue.Field(i).Interface(), newValue.Field(i).Interface()
+		if !reflect.DeepEqual(before, after) {
+			diffs = append(diffs, FieldDiff{Field: field, Old: before, New: after})
+		}
+	}
+
+	return diffs
+}
+
+// diffEnvironment reports added, removed and changed variables, sorted by key
+func diffEnvironment(oldEnv, newEnv map[string]string) []FieldDiff {
+	keys := make(map[string]bool)
+	for key := range oldEnv {
+		keys[key] = true
+	}
+	for key := range newEnv {
+		keys[key] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+
+	var diffs []FieldDiff
+	for _, key := range sorted {
+		before, inOld := oldEnv[key]
+		after, inNew := newEnv[key]
+		if inOld && inNew && before == after {
+			continue
+		}
+
+		diff := FieldDiff{Field: "Environment." + key}
+		if inOld {
+			diff.Old = before
+		}
+		if inNew {
+			diff.New = after
+		}
+		diffs = append(diffs, diff)
+	}
+	return diffs
+}
@@ -0,0 +1,97 @@
+package thirdpartyhosting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffAddedService(t *testing.T) {
+	provider, _ := newTestProvider(t, testConfig(), nil)
+	newConfig := testConfig()
+	newConfig.Services["cache"] = ServiceConfig{ImageName: "redis"}
+
+	diffs, err := provider.Diff(newConfig)
+
+	require.NoError(t, err)
+	assert.Equal(t, []ServiceDiff{{Service: "cache", Change: ServiceAdded}}, diffs)
+}
+
+func TestDiffRemovedService(t *testing.T) {
+	provider, _ := newTestProvider(t, testConfig(), nil)
+	newConfig := testConfig()
+	app := newConfig.Services["app"]
+	app.DependsOn = nil
+	newConfig.Services["app"] = app
+	delete(newConfig.Services, "db")
+
+	diffs, err := provider.Diff(newConfig)
+
+	require.NoError(t, err)
+	assert.Equal(t, []ServiceDiff{
+		{Service: "app", Change: ServiceChanged, Fields: []FieldDiff{{Field: "DependsOn", Old: []string{"db"}, New: []string(nil)}}},
+		{Service: "db", Change: ServiceRemoved},
+	}, diffs)
+}
+
+func TestDiffChangedEnvironment(t *testing.T) {
+	config := testConfig()
+	app := config.Services["app"]
+	app.Environment = map[string]string{"LOG_LEVEL": "info", "OLD": "1"}
+	config.Services["app"] = app
+	provider, _ := newTestProvider(t, config, nil)
+
+	newConfig := config.Clone()
+	app = newConfig.Services["app"]
+	app.Environment = map[string]string{"LOG_LEVEL": "debug", "NEW": "2"}
+	app.ImageTag = "v2"
+	newConfig.Services["app"] = app
+
+	diffs, err := provider.Diff(newConfig)
+
+	require.NoError(t, err)
+	assert.Equal(t, []ServiceDiff{{
+		Service: "app",
+		Change:  ServiceChanged,
+		Fields: []FieldDiff{
+			{Field: "ImageTag", Old: "latest", New: "v2"},
+			{Field: "Environment.LOG_LEVEL", Old: "info", New: "debug"},
+			{Field: "Environment.NEW", New: "2"},
+			{Field: "Environment.OLD", Old: "1"},
+		},
+	}}, diffs)
+}
+
+func TestDiffUnchanged(t *testing.T) {
+	provider, _ := newTestProvider(t, testConfig(), nil)
+
+	diffs, err := provider.Diff(testConfig())
+
+	require.NoError(t, err)
+	assert.Empty(t, diffs)
+}
+
+func TestDiffUnchangedWithExtends(t *testing.T) {
+	config := func() ComposeConfig {
+		return ComposeConfig{Services: map[string]ServiceConfig{
+			"base":   {ImageName: "app", ImageTag: "1.0", Environment: map[string]string{"MODE": "prod"}},
+			"worker": {Extends: "base", Command: []string{"work"}},
+		}}
+	}
+	provider, _ := newTestProvider(t, config(), nil)
+
+	diffs, err := provider.Diff(config())
+
+	require.NoError(t, err)
+	assert.Empty(t, diffs)
+}
+
+func TestDiffErrors(t *testing.T) {
+	_, err := NewDockerComposeProvider().Diff(testConfig())
+	assert.Error(t, err)
+
+	provider, _ := newTestProvider(t, testConfig(), nil)
+	_, err = provider.Diff(ComposeConfig{Services: map[string]ServiceConfig{"app": {}}})
+	assert.Error(t, err)
+}
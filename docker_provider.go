@@ -5,28 +5,121 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"os/exec"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 )
 
 // DockerComposeProvider implements the DockerProvider interface using docker-compose
 type DockerComposeProvider struct {
-	config      ComposeConfig
-	initialized bool
-	containers  map[string]string // service name -> container ID
-	mu          sync.RWMutex
+	// Progress, when set, receives an event for each step docker-compose reports during Start.
+	// Sends block, so the receiver must keep draining it until Start returns.
+	Progress chan<- ProgressEvent
+
+	// Output, when set, receives docker-compose's output for up and down as it is produced.
+	// Output is still captured for error messages either way.
+	Output io.Writer
+
+	// ComposeFileName names the generated compose file passed to docker-compose -f, for
+	// tooling that expects a particular name; empty uses docker-compose.yml
+	ComposeFileName string
+
+	// CheckPorts makes Start verify that every fixed host port is free before running
+	// docker-compose, failing with an error naming the service and port. Ports held by
+	// this stack's own running containers count as taken, so enable it for cold starts.
+	CheckPorts bool
+
+	// PullOnStart runs Pull before every Start so floating tags such as latest are refreshed
+	PullOnStart bool
+
+	// IgnorePullFailure lets Start continue with the local images when PullOnStart's pull fails
+	IgnorePullFailure bool
+
+	// Timeouts bounds the docker-compose calls made by Start, Stop, Status and Pull
+	Timeouts Timeouts
+
+	// StopTimeout is how long Stop gives containers to shut down before they are killed,
+	// passed to docker-compose down -t in whole seconds (rounded up); 0 uses compose's default of 10s
+	StopTimeout time.Duration
+
+	// ContainerIDWait is how long Start keeps polling docker-compose ps for the services it
+	// brought up that don't report a container ID yet, as containers can still be being
+	// created when up returns; 0 reads the IDs once
+	ContainerIDWait time.Duration
+
+	// SwarmMode renders swarm-only deploy settings as written. When false, the default,
+	// Start translates them for local docker-compose: deploy.restart_policy becomes restart
+	// and deploy.replicas becomes --scale; see Warnings for what cannot be translated.
+	SwarmMode bool
+
+	// InspectBatchSize caps how many containers a single docker inspect call covers when
+	// checking status; 0 uses a default of 50
+	InspectBatchSize int
+
+	// Inspector, when set, looks up container state instead of docker inspect; see
+	// ContainerInspector and the dockerapi package
+	Inspector ContainerInspector
+
+	// InheritEnv passes this process's environment to docker and docker-compose commands.
+	// NewDockerComposeProvider enables it; with it off, commands see only ExtraEnv, e.g.
+	// for CI runners whose environment must not leak into docker.
+	InheritEnv bool
+
+	// ExtraEnv sets variables for docker and docker-compose commands, such as DOCKER_HOST
+	// or DOCKER_CONTEXT for a rootless daemon; it overrides inherited values
+	ExtraEnv map[string]string
+
+	// Logger, when set, receives a debug entry for every docker command the provider runs,
+	// with its duration and exit status; nil disables logging
+	Logger Logger
+
+	// OnOperation, when set, is called after each Start, Stop, Status and Pull with the
+	// operation name ("start", "stop", "status" or "pull"), its duration and its error
+	OnOperation func(op string, dur time.Duration, err error)
+
+	// PreStart, when set, runs before Start brings services up; an error aborts Start
+	PreStart func(ctx context.Context) error
+
+	// PostStop, when set, runs after Stop has torn services down; its error is returned by Stop
+	PostStop func(ctx context.Context) error
+
+	config       ComposeConfig
+	initialized  bool
+	containers   map[string][]string // service name -> container IDs, several when scaled
+	startedAt    time.Time           // when the last Start brought services up; zero before the first
+	fileVersion  string              // default compose file version for the binary Validate found
+	runner       commandRunner
+	pollInterval time.Duration // delay between readiness checks
+	mu           sync.RWMutex
 }
 
+// containerIDRetryInterval is the delay between the ps calls made while waiting for container IDs
+const containerIDRetryInterval = 100 * time.Millisecond
+
 // NewDockerComposeProvider creates a new Docker Compose provider
 func NewDockerComposeProvider() *DockerComposeProvider {
 	return &DockerComposeProvider{
-		containers: make(map[string]string),
+		containers:   make(map[string][]string),
+		InheritEnv:   true,
+		runner:       execRunner{},
+		pollInterval: time.Second,
 	}
 }
 
 // Initialize sets up the Docker environment and validates the configuration
 func (p *DockerComposeProvider) Initialize(ctx context.Context, config ComposeConfig) error {
+	if err := ValidateConfig(config); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	// Services that extend others are stored resolved, so every operation sees their
+	// inherited settings
+	config, err := resolveExtends(config.Clone())
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
@@ -35,8 +128,61 @@ func (p *DockerComposeProvider) Initialize(ctx context.Context, config ComposeCo
 	return nil
 }
 
+// Reattach initializes the provider for a stack that is already running, e.g. after the
+// host process restarted, and rebuilds the container ID cache from docker-compose ps
+// without running up. It fails if no containers of the project are found.
+func (p *DockerComposeProvider) Reattach(ctx context.Context, config ComposeConfig) error {
+	if err := p.Initialize(ctx, config); err != nil {
+		return err
+	}
+
+	if err := p.updateContainerIDs(ctx); err != nil {
+		return err
+	}
+
+	p.mu.RLock()
+	found := len(p.containers)
+	p.mu.RUnlock()
+
+	if found == 0 {
+		return fmt.Errorf("no containers found for project %s", normalizeProjectName(config.ProjectName))
+	}
+	return nil
+}
+
+// Validate checks the initialized configuration and that docker-compose is available
+func (p *DockerComposeProvider) Validate(ctx context.Context) error {
+	p.mu.RLock()
+	if !p.initialized {
+		p.mu.RUnlock()
+		return fmt.Errorf("provider not initialized")
+	}
+	config := p.config
+	p.mu.RUnlock()
+
+	if err := ValidateConfig(config); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	output, err := p.commands().Run(ctx, "docker-compose", "version")
+	if err != nil {
+		return fmt.Errorf("docker-compose is not available: %s, error: %w", string(output), err)
+	}
+
+	// The binary's version decides the default compose file version
+	if version := detectComposeVersion(output); version != "" {
+		p.mu.Lock()
+		p.fileVersion = version
+		p.mu.Unlock()
+	}
+
+	return nil
+}
+
 // Start creates and starts all Docker containers defined in the compose configuration
-func (p *DockerComposeProvider) Start(ctx context.Context) error {
+func (p *DockerComposeProvider) Start(ctx context.Context) (err error) {
+	defer p.observe("start", time.Now(), &err)
+
 	p.mu.RLock()
 	if !p.initialized {
 		p.mu.RUnlock()
@@ -45,25 +191,83 @@ func (p *DockerComposeProvider) Start(ctx context.Context) error {
 	config := p.config
 	p.mu.RUnlock()
 
+	if p.CheckPorts {
+		if err := checkPortsAvailable(config); err != nil {
+			return err
+		}
+	}
+
+	if err := p.runPreStart(ctx); err != nil {
+		return err
+	}
+
+	if p.PullOnStart {
+		if err := p.Pull(ctx); err != nil && !p.IgnorePullFailure {
+			return err
+		}
+	}
+
+	ctx, cancel := p.Timeouts.withTimeout(ctx, p.Timeouts.Start)
+	defer cancel()
+
+	p.mu.Lock()
+	p.startedAt = time.Now()
+	p.mu.Unlock()
+
+	return p.up(ctx, config)
+}
+
+// observe reports a finished operation to OnOperation if one is set
+func (p *DockerComposeProvider) observe(op string, start time.Time, err *error) {
+	if p.OnOperation != nil {
+		p.OnOperation(op, time.Since(start), *err)
+	}
+}
+
+// runPreStart runs the PreStart hook if one is set
+func (p *DockerComposeProvider) runPreStart(ctx context.Context) error {
+	if p.PreStart != nil {
+		if err := p.PreStart(ctx); err != nil {
+			return fmt.Errorf("pre-start hook failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// up runs docker-compose up -d with any extra args, such as the services to start; with none it starts everything
+func (p *DockerComposeProvider) up(ctx context.Context, config ComposeConfig, args ...string) error {
+	services := upServices(args)
+
 	// Generate docker-compose.yml file
-	composeFile, err := generateComposeFile(config)
+	composeFile, scale, err := p.renderComposeFile(config, services)
 	if err != nil {
-		return fmt.Errorf("failed to generate compose file: %w", err)
+		return err
 	}
+	args = append(scale, args...)
 
 	// Run docker-compose up
-	cmd := exec.CommandContext(ctx, "docker-compose", "-p", config.ProjectName, "-f", composeFile, "up", "-d")
-	output, err := cmd.CombinedOutput()
+	var progress *progressWriter
+	var extra io.Writer
+	if p.Progress != nil {
+		progress = &progressWriter{ctx: ctx, project: normalizeProjectName(config.ProjectName), services: config.Services, events: p.Progress}
+		extra = progress
+	}
+	output, err := p.runCompose(ctx, extra, composeArgs(config, composeFile, append([]string{"up", "-d"}, args...)...)...)
+	if progress != nil {
+		progress.Flush()
+	}
 	if err != nil {
 		return fmt.Errorf("failed to start containers: %s, error: %w", string(output), err)
 	}
 
 	// Update container IDs
-	return p.updateContainerIDs(ctx)
+	return p.waitForContainerIDs(ctx, config, services)
 }
 
 // Stop gracefully stops and removes all Docker containers
-func (p *DockerComposeProvider) Stop(ctx context.Context) error {
+func (p *DockerComposeProvider) Stop(ctx context.Context) (err error) {
+	defer p.observe("stop", time.Now(), &err)
+
 	p.mu.RLock()
 	if !p.initialized {
 		p.mu.RUnlock()
@@ -72,28 +276,47 @@ func (p *DockerComposeProvider) Stop(ctx context.Context) error {
 	config := p.config
 	p.mu.RUnlock()
 
+	if p.StopTimeout < 0 {
+		return fmt.Errorf("stop timeout must not be negative, got %v", p.StopTimeout)
+	}
+
 	// Generate docker-compose.yml file
-	composeFile, err := generateComposeFile(config)
+	composeFile, _, err := p.renderComposeFile(config, nil)
 	if err != nil {
-		return fmt.Errorf("failed to generate compose file: %w", err)
+		return err
+	}
+
+	args := []string{"down"}
+	if p.StopTimeout > 0 {
+		seconds := int64((p.StopTimeout + time.Second - 1) / time.Second)
+		args = append(args, "-t", strconv.FormatInt(seconds, 10))
 	}
 
 	// Run docker-compose down
-	cmd := exec.CommandContext(ctx, "docker-compose", "-p", config.ProjectName, "-f", composeFile, "down")
-	output, err := cmd.CombinedOutput()
+	downCtx, cancel := p.Timeouts.withTimeout(ctx, p.Timeouts.Stop)
+	output, err := p.runCompose(downCtx, nil, composeArgs(config, composeFile, args...)...)
+	cancel()
 	if err != nil {
 		return fmt.Errorf("failed to stop containers: %s, error: %w", string(output), err)
 	}
 
 	p.mu.Lock()
-	p.containers = make(map[string]string)
+	p.containers = make(map[string][]string)
 	p.mu.Unlock()
 
+	if p.PostStop != nil {
+		if err := p.PostStop(ctx); err != nil {
+			return fmt.Errorf("post-stop hook failed: %w", err)
+		}
+	}
+
 	return nil
 }
 
 // Status returns the current status of all Docker containers
-func (p *DockerComposeProvider) Status(ctx context.Context) (map[string]string, error) {
+func (p *DockerComposeProvider) Status(ctx context.Context) (_ map[string]string, err error) {
+	defer p.observe("status", time.Now(), &err)
+
 	p.mu.RLock()
 	if !p.initialized {
 		p.mu.RUnlock()
@@ -102,79 +325,125 @@ func (p *DockerComposeProvider) Status(ctx context.Context) (map[string]string,
 	config := p.config
 	p.mu.RUnlock()
 
+	ctx, cancel := p.Timeouts.withTimeout(ctx, p.Timeouts.Status)
+	defer cancel()
+
 	// Update container IDs first
 	if err := p.updateContainerIDs(ctx); err != nil {
 		return nil, err
 	}
 
 	p.mu.RLock()
-	defer p.mu.RUnlock()
+	containers := make(map[string][]string, len(p.containers))
+	containerIDs := make([]string, 0, len(p.containers))
+	for service, ids := range p.containers {
+		containers[service] = ids
+		containerIDs = append(containerIDs, ids...)
+	}
+	p.mu.RUnlock()
+
+	states := p.inspectStates(ctx, containerIDs)
+
+	return serviceStatuses(config, containers, states), nil
+}
 
+// serviceStatuses combines the services in config, their container IDs and the inspected
+// container states into the statuses reported by Status. A scaled service reports the
+// status of its first container that is not up, or of its first container when all are.
+func serviceStatuses(config ComposeConfig, containers map[string][]string, states map[string]ContainerState) map[string]string {
 	statuses := make(map[string]string)
-	for service := range config.Services {
-		containerID, exists := p.containers[service]
-		if !exists {
-			statuses[service] = "not_found"
+	for service, serviceConfig := range config.Services {
+		if !isServiceActive(config, serviceConfig) {
+			statuses[service] = "disabled"
 			continue
 		}
 
-		cmd := exec.CommandContext(ctx, "docker", "inspect", "--format", "{{.State.Status}}", containerID)
-		output, err := cmd.CombinedOutput()
-		if err != nil {
-			statuses[service] = "error"
+		containerIDs := containers[service]
+		if len(containerIDs) == 0 {
+			statuses[service] = "not_found"
 			continue
 		}
 
-		status := strings.TrimSpace(string(output))
-		statuses[service] = status
+		for i, containerID := range containerIDs {
+			status := "error"
+			if state, ok := states[containerID]; ok {
+				status = state.serviceStatus()
+			}
+			if i == 0 {
+				statuses[service] = status
+			}
+			if status != "running" && status != "healthy" {
+				statuses[service] = status
+				break
+			}
+		}
 	}
 
-	return statuses, nil
+	return statuses
 }
 
 // GetLogs retrieves Docker container logs for a specific service
 func (p *DockerComposeProvider) GetLogs(ctx context.Context, serviceName string) (io.Reader, error) {
-	p.mu.RLock()
-	if !p.initialized {
-		p.mu.RUnlock()
-		return nil, fmt.Errorf("provider not initialized")
-	}
-	config := p.config
-	p.mu.RUnlock()
-
-	// Check if service exists
-	if _, exists := config.Services[serviceName]; !exists {
-		return nil, fmt.Errorf("service %s not found", serviceName)
+	containerID, err := p.ResolveContainerID(ctx, serviceName)
+	if err != nil {
+		return nil, err
 	}
 
-	// Update container IDs first
-	if err := p.updateContainerIDs(ctx); err != nil {
-		return nil, err
+	output, err := p.commands().Run(ctx, "docker", "logs", containerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get logs: %w", err)
 	}
 
-	p.mu.RLock()
-	containerID, exists := p.containers[serviceName]
-	p.mu.RUnlock()
+	return bytes.NewReader(output), nil
+}
 
-	if !exists {
-		return nil, fmt.Errorf("container for service %s not found", serviceName)
+// RemoveService stops and removes a single service's container, leaving the rest of the stack running.
+// With force the container is killed immediately instead of being given time to shut down.
+func (p *DockerComposeProvider) RemoveService(ctx context.Context, serviceName string, force bool) error {
+	containerIDs, err := p.resolveContainerIDs(ctx, serviceName)
+	if err != nil {
+		return err
 	}
 
-	cmd := exec.CommandContext(ctx, "docker", "logs", containerID)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get logs: %w", err)
+	if force {
+		if output, err := p.commands().Run(ctx, "docker", append([]string{"rm", "-f"}, containerIDs...)...); err != nil {
+			return fmt.Errorf("failed to remove container for service %s: %s, error: %w", serviceName, string(output), err)
+		}
+	} else {
+		if output, err := p.commands().Run(ctx, "docker", append([]string{"stop"}, containerIDs...)...); err != nil {
+			return fmt.Errorf("failed to stop container for service %s: %s, error: %w", serviceName, string(output), err)
+		}
+		if output, err := p.commands().Run(ctx, "docker", append([]string{"rm"}, containerIDs...)...); err != nil {
+			return fmt.Errorf("failed to remove container for service %s: %s, error: %w", serviceName, string(output), err)
+		}
 	}
 
-	return bytes.NewReader(output), nil
+	p.mu.Lock()
+	delete(p.containers, serviceName)
+	p.mu.Unlock()
+
+	return nil
 }
 
-// GetContainerID returns the Docker container ID for a specific service
+// GetContainerID returns the Docker container ID for a specific service; for a service
+// scaled to several containers it is the first one, see GetContainerIDs
 func (p *DockerComposeProvider) GetContainerID(serviceName string) string {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
 
-	return p.containers[serviceName]
+	if ids := p.containers[serviceName]; len(ids) > 0 {
+		return ids[0]
+	}
+	return ""
+}
+
+// GetContainerIDs returns the Docker container IDs of all of a service's containers,
+// more than one when it is scaled, e.g. through DeployConfig.Replicas
+func (p *DockerComposeProvider) GetContainerIDs(serviceName string) []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return append([]string(nil), p.containers[serviceName]...)
 }
 
 // GetServices returns all service names currently managed by this provider
@@ -194,28 +463,116 @@ func (p *DockerComposeProvider) GetServices() []string {
 	return services
 }
 
-// updateContainerIDs refreshes the container IDs for all services
+// ServiceExists reports whether the initialized configuration defines the named service
+func (p *DockerComposeProvider) ServiceExists(name string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if !p.initialized {
+		return false
+	}
+
+	_, ok := p.config.Services[name]
+	return ok
+}
+
+// ResolveContainerID refreshes the container IDs and returns the one for serviceName, the
+// first one when the service is scaled. Unlike GetContainerID it sees containers that were
+// created or replaced since the last refresh.
+func (p *DockerComposeProvider) ResolveContainerID(ctx context.Context, serviceName string) (string, error) {
+	containerIDs, err := p.resolveContainerIDs(ctx, serviceName)
+	if err != nil {
+		return "", err
+	}
+	return containerIDs[0], nil
+}
+
+// resolveContainerIDs refreshes the container IDs and returns all of serviceName's
+func (p *DockerComposeProvider) resolveContainerIDs(ctx context.Context, serviceName string) ([]string, error) {
+	p.mu.RLock()
+	if !p.initialized {
+		p.mu.RUnlock()
+		return nil, fmt.Errorf("provider not initialized")
+	}
+	config := p.config
+	p.mu.RUnlock()
+
+	// Check if service exists
+	if _, exists := config.Services[serviceName]; !exists {
+		return nil, fmt.Errorf("service %s not found", serviceName)
+	}
+
+	// Update container IDs first
+	if err := p.updateContainerIDs(ctx); err != nil {
+		return nil, err
+	}
+
+	p.mu.RLock()
+	containerIDs := p.containers[serviceName]
+	p.mu.RUnlock()
+
+	if len(containerIDs) == 0 {
+		return nil, fmt.Errorf("container for service %s not found", serviceName)
+	}
+
+	return containerIDs, nil
+}
+
+// runCompose runs docker-compose and returns its combined output. When Output or
+// extra is set the output is also written to them as it arrives.
+func (p *DockerComposeProvider) runCompose(ctx context.Context, extra io.Writer, args ...string) ([]byte, error) {
+	if p.Output == nil && extra == nil {
+		return p.commands().Run(ctx, "docker-compose", args...)
+	}
+
+	var output bytes.Buffer
+	writers := []io.Writer{&output}
+	if p.Output != nil {
+		writers = append(writers, p.Output)
+	}
+	if extra != nil {
+		writers = append(writers, extra)
+	}
+
+	err := p.commands().Stream(ctx, nil, io.MultiWriter(writers...), "docker-compose", args...)
+	return output.Bytes(), err
+}
+
+// composeArgs builds the global docker-compose flags for config followed by args.
+// The -f flag is omitted when composeFile is empty.
+func composeArgs(config ComposeConfig, composeFile string, args ...string) []string {
+	global := []string{"-p", normalizeProjectName(config.ProjectName)}
+	if composeFile != "" {
+		global = append(global, "-f", composeFile)
+	}
+	for _, profile := range config.ActiveProfiles {
+		global = append(global, "--profile", profile)
+	}
+
+	return append(global, args...)
+}
+
+// updateContainerIDs refreshes the container IDs for all services. The cache is rebuilt
+// from docker-compose ps rather than updated, so containers that exited and were removed,
+// such as finished one-shot services, drop out instead of leaving stale IDs behind.
 func (p *DockerComposeProvider) updateContainerIDs(ctx context.Context) error {
 	p.mu.RLock()
 	config := p.config
 	p.mu.RUnlock()
 
-	containers := make(map[string]string)
-	for service := range config.Services {
-		cmd := exec.CommandContext(
-			ctx,
-			"docker-compose",
-			"-p", config.ProjectName,
-			"ps", "-q", service,
-		)
-		output, err := cmd.CombinedOutput()
+	containers := make(map[string][]string)
+	for service, serviceConfig := range config.Services {
+		if !isServiceActive(config, serviceConfig) {
+			continue
+		}
+
+		output, err := p.commands().Run(ctx, "docker-compose", composeArgs(config, "", "ps", "-q", service)...)
 		if err != nil {
 			continue // Skip if service not running
 		}
 
-		containerID := strings.TrimSpace(string(output))
-		if containerID != "" {
-			containers[service] = containerID
+		if containerIDs := parseContainerIDs(output); len(containerIDs) > 0 {
+			containers[service] = containerIDs
 		}
 	}
 
@@ -225,3 +582,47 @@ func (p *DockerComposeProvider) updateContainerIDs(ctx context.Context) error {
 
 	return nil
 }
+
+// parseContainerIDs splits docker-compose ps -q output, one ID per line and several for a
+// scaled service
+func parseContainerIDs(output []byte) []string {
+	return strings.Fields(string(output))
+}
+
+// waitForContainerIDs refreshes the container IDs after up, retrying for up to
+// ContainerIDWait until every service up started has one: the services named to up,
+// or every active one when none were
+func (p *DockerComposeProvider) waitForContainerIDs(ctx context.Context, config ComposeConfig, services []string) error {
+	expected := services
+	if len(expected) == 0 {
+		for name, service := range config.Services {
+			if isServiceActive(config, service) {
+				expected = append(expected, name)
+			}
+		}
+	}
+
+	deadline := time.Now().Add(p.ContainerIDWait)
+	for {
+		if err := p.updateContainerIDs(ctx); err != nil {
+			return err
+		}
+
+		missing := false
+		for _, service := range expected {
+			if p.GetContainerID(service) == "" {
+				missing = true
+				break
+			}
+		}
+		if !missing || !time.Now().Before(deadline) {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil // the IDs are refreshed again by the next call that needs them
+		case <-time.After(containerIDRetryInterval):
+		}
+	}
+}
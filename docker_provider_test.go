@@ -0,0 +1,447 @@
+package thirdpartyhosting
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRunner records the commands issued by the provider and answers them through handler
+type fakeRunner struct {
+	mu      sync.Mutex
+	calls   []string
+	inputs  []string // stdin passed to Stream, in call order
+	handler func(ctx context.Context, command string) ([]byte, error)
+}
+
+func (f *fakeRunner) Run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	command := strings.Join(append([]string{name}, args...), " ")
+
+	f.mu.Lock()
+	f.calls = append(f.calls, command)
+	handler := f.handler
+	f.mu.Unlock()
+
+	if handler == nil {
+		return nil, nil
+	}
+	return handler(ctx, command)
+}
+
+func (f *fakeRunner) Stream(ctx context.Context, stdin io.Reader, output io.Writer, name string, args ...string) error {
+	if stdin != nil {
+		input, _ := io.ReadAll(stdin)
+		f.mu.Lock()
+		f.inputs = append(f.inputs, string(input))
+		f.mu.Unlock()
+	}
+
+	result, err := f.Run(ctx, name, args...)
+	if len(result) > 0 {
+		output.Write(result)
+	}
+	return err
+}
+
+// Calls returns a copy of the commands run so far
+func (f *fakeRunner) Calls() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]string(nil), f.calls...)
+}
+
+// CallsContaining returns the commands that contain substr
+func (f *fakeRunner) CallsContaining(substr string) []string {
+	var matched []string
+	for _, call := range f.Calls() {
+		if strings.Contains(call, substr) {
+			matched = append(matched, call)
+		}
+	}
+	return matched
+}
+
+// newTestProvider returns an initialized provider backed by a fakeRunner
+func newTestProvider(t *testing.T, config ComposeConfig, handler func(ctx context.Context, command string) ([]byte, error)) (*DockerComposeProvider, *fakeRunner) {
+	t.Helper()
+
+	runner := &fakeRunner{handler: handler}
+	provider := NewDockerComposeProvider()
+	provider.runner = runner
+
+	require.NoError(t, provider.Initialize(context.Background(), config))
+	return provider, runner
+}
+
+// testConfig returns a small two-service configuration
+func testConfig() ComposeConfig {
+	return ComposeConfig{
+		ProjectName: "test-project",
+		Services: map[string]ServiceConfig{
+			"app": {ImageName: "app", ImageTag: "latest", DependsOn: []string{"db"}},
+			"db":  {ImageName: "postgres", ImageTag: "13"},
+		},
+	}
+}
+
+func TestDockerComposeProviderStart(t *testing.T) {
+	provider, runner := newTestProvider(t, testConfig(), func(ctx context.Context, command string) ([]byte, error) {
+		if strings.Contains(command, "ps -q app") {
+			return []byte("abc123\n"), nil
+		}
+		return nil, nil
+	})
+
+	err := provider.Start(context.Background())
+
+	assert.NoError(t, err)
+	assert.Len(t, runner.CallsContaining("up -d"), 1)
+	assert.Equal(t, "abc123", provider.GetContainerID("app"))
+	assert.Empty(t, provider.GetContainerID("db"))
+}
+
+func TestDockerComposeProviderNotInitialized(t *testing.T) {
+	provider := NewDockerComposeProvider()
+
+	assert.Error(t, provider.Start(context.Background()))
+	assert.Error(t, provider.Stop(context.Background()))
+}
+
+func TestStatusReportsInactiveProfileAsDisabled(t *testing.T) {
+	config := testConfig()
+	config.Services["debug"] = ServiceConfig{ImageName: "busybox", Profiles: []string{"debug"}}
+
+	provider, runner := newTestProvider(t, config, healthHandler(map[string]string{
+		"app": "running",
+		"db":  "running",
+	}))
+
+	statuses, err := provider.Status(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"app": "running", "db": "running", "debug": "disabled"}, statuses)
+	assert.Empty(t, runner.CallsContaining("ps -q debug"))
+}
+
+func TestStatusWithActiveProfile(t *testing.T) {
+	config := testConfig()
+	config.ActiveProfiles = []string{"debug"}
+	config.Services["debug"] = ServiceConfig{ImageName: "busybox", Profiles: []string{"debug"}}
+
+	provider, runner := newTestProvider(t, config, healthHandler(map[string]string{
+		"app": "running",
+		"db":  "running",
+	}))
+
+	statuses, err := provider.Status(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, "not_found", statuses["debug"])
+	assert.Contains(t, runner.CallsContaining("ps -q debug")[0], "--profile debug")
+}
+
+func TestDockerComposeProviderValidate(t *testing.T) {
+	provider, runner := newTestProvider(t, testConfig(), nil)
+
+	assert.NoError(t, provider.Validate(context.Background()))
+	assert.Equal(t, []string{"docker-compose version"}, runner.Calls())
+}
+
+func TestDockerComposeProviderValidateMissingCompose(t *testing.T) {
+	provider, _ := newTestProvider(t, testConfig(), func(ctx context.Context, command string) ([]byte, error) {
+		return nil, errors.New(`exec: "docker-compose": executable file not found in $PATH`)
+	})
+
+	err := provider.Validate(context.Background())
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "docker-compose is not available")
+}
+
+func TestDockerComposeProviderValidateNotInitialized(t *testing.T) {
+	var provider DockerProvider = NewDockerComposeProvider()
+
+	assert.Error(t, provider.Validate(context.Background()))
+}
+
+func TestOutputWriterReceivesComposeOutput(t *testing.T) {
+	provider, _ := newTestProvider(t, testConfig(), func(ctx context.Context, command string) ([]byte, error) {
+		switch {
+		case strings.Contains(command, "up -d"):
+			return []byte("Creating test-project_db_1 ... done\n"), nil
+		case strings.HasSuffix(command, " down"):
+			return []byte("Removing test-project_db_1 ... done\n"), nil
+		}
+		return nil, nil
+	})
+
+	var output strings.Builder
+	provider.Output = &output
+
+	assert.NoError(t, provider.Start(context.Background()))
+	assert.NoError(t, provider.Stop(context.Background()))
+
+	assert.Equal(t, "Creating test-project_db_1 ... done\nRemoving test-project_db_1 ... done\n", output.String())
+}
+
+func TestOutputWriterUnsetStaysSilent(t *testing.T) {
+	provider, runner := newTestProvider(t, testConfig(), func(ctx context.Context, command string) ([]byte, error) {
+		return []byte("noise\n"), errors.New("exit status 1")
+	})
+
+	err := provider.Start(context.Background())
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "noise")
+	assert.Len(t, runner.Calls(), 1)
+}
+
+func TestRemoveServiceForce(t *testing.T) {
+	provider, runner := newTestProvider(t, testConfig(), healthHandler(map[string]string{
+		"app": "running",
+		"db":  "running",
+	}))
+
+	err := provider.RemoveService(context.Background(), "app", true)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"docker rm -f id-app"}, runner.CallsContaining("docker rm"))
+	assert.Empty(t, runner.CallsContaining("docker stop"))
+	assert.Empty(t, provider.GetContainerID("app"))
+	assert.Equal(t, "id-db", provider.GetContainerID("db"))
+}
+
+func TestRemoveServiceGraceful(t *testing.T) {
+	provider, runner := newTestProvider(t, testConfig(), healthHandler(map[string]string{
+		"app": "running",
+		"db":  "running",
+	}))
+
+	err := provider.RemoveService(context.Background(), "db", false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"docker stop id-db", "docker rm id-db"}, runner.CallsContaining("docker "))
+	assert.Empty(t, provider.GetContainerID("db"))
+	assert.Equal(t, "id-app", provider.GetContainerID("app"))
+}
+
+func TestRemoveServiceUnknown(t *testing.T) {
+	provider, _ := newTestProvider(t, testConfig(), nil)
+
+	assert.Error(t, provider.RemoveService(context.Background(), "missing", true))
+}
+
+func TestStatusFoldsHealth(t *testing.T) {
+	config := testConfig()
+	config.Services["worker"] = ServiceConfig{ImageName: "worker"}
+	config.Services["cache"] = ServiceConfig{ImageName: "redis"}
+
+	provider, _ := newTestProvider(t, config, healthHandler(map[string]string{
+		"app":    "healthy",
+		"db":     "unhealthy",
+		"worker": "running",
+		"cache":  "exited",
+	}))
+
+	statuses, err := provider.Status(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"app":    "healthy",
+		"db":     "unhealthy",
+		"worker": "running",
+		"cache":  "exited",
+	}, statuses)
+}
+
+func TestReattach(t *testing.T) {
+	runner := &fakeRunner{handler: func(ctx context.Context, command string) ([]byte, error) {
+		switch {
+		case strings.HasSuffix(command, "-p test-project ps -q app"):
+			return []byte("running-app\n"), nil
+		case strings.HasSuffix(command, "-p test-project ps -q db"):
+			return []byte("running-db\n"), nil
+		}
+		return nil, nil
+	}}
+	provider := NewDockerComposeProvider()
+	provider.runner = runner
+
+	err := provider.Reattach(context.Background(), testConfig())
+
+	assert.NoError(t, err)
+	assert.Equal(t, "running-app", provider.GetContainerID("app"))
+	assert.Equal(t, "running-db", provider.GetContainerID("db"))
+	assert.Empty(t, runner.CallsContaining(" up "))
+
+	assert.NoError(t, provider.Stop(context.Background()))
+	assert.Len(t, runner.CallsContaining("-p test-project -f "), 1)
+}
+
+func TestReattachNothingRunning(t *testing.T) {
+	provider := NewDockerComposeProvider()
+	provider.runner = &fakeRunner{}
+
+	err := provider.Reattach(context.Background(), testConfig())
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "no containers found for project test-project")
+}
+
+func TestServiceExists(t *testing.T) {
+	provider, _ := newTestProvider(t, testConfig(), nil)
+
+	assert.True(t, provider.ServiceExists("app"))
+	assert.True(t, provider.ServiceExists("db"))
+	assert.False(t, provider.ServiceExists("cache"))
+}
+
+func TestServiceExistsNotInitialized(t *testing.T) {
+	provider := NewDockerComposeProvider()
+
+	assert.False(t, provider.ServiceExists("app"))
+}
+
+func TestComposeFileNameUsedAcrossOperations(t *testing.T) {
+	provider, runner := newTestProvider(t, testConfig(), healthHandler(map[string]string{"app": "running", "db": "running"}))
+	provider.ComposeFileName = "docker-compose.generated.yml"
+
+	require.NoError(t, provider.Start(context.Background()))
+	_, err := provider.Status(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, provider.Stop(context.Background()))
+
+	var files []string
+	for _, call := range runner.Calls() {
+		fields := strings.Fields(call)
+		for i, field := range fields {
+			if field == "-f" {
+				files = append(files, fields[i+1])
+			}
+		}
+	}
+	require.Len(t, files, 2, "up and down")
+	for _, file := range files {
+		assert.True(t, strings.HasSuffix(file, "/docker-compose.generated.yml"), file)
+	}
+}
+
+func TestComposeFileNameRejectsPaths(t *testing.T) {
+	provider, runner := newTestProvider(t, testConfig(), nil)
+	provider.ComposeFileName = "../docker-compose.yml"
+
+	assert.Error(t, provider.Start(context.Background()))
+	assert.Empty(t, runner.CallsContaining("up -d"))
+}
+
+func TestStopTimeout(t *testing.T) {
+	for timeout, expected := range map[time.Duration]string{
+		0:                       " down",
+		30 * time.Second:        " down -t 30",
+		1500 * time.Millisecond: " down -t 2",
+	} {
+		provider, runner := newTestProvider(t, testConfig(), nil)
+		provider.StopTimeout = timeout
+
+		require.NoError(t, provider.Stop(context.Background()))
+
+		calls := runner.CallsContaining(" down")
+		require.Len(t, calls, 1)
+		assert.True(t, strings.HasSuffix(calls[0], expected), "%v: %s", timeout, calls[0])
+	}
+}
+
+func TestStopTimeoutNegative(t *testing.T) {
+	provider, runner := newTestProvider(t, testConfig(), nil)
+	provider.StopTimeout = -time.Second
+
+	assert.Error(t, provider.Stop(context.Background()))
+	assert.Empty(t, runner.Calls())
+}
+
+func TestStartRetriesEmptyContainerIDs(t *testing.T) {
+	var mu sync.Mutex
+	attempts := map[string]int{}
+	provider, runner := newTestProvider(t, testConfig(), func(ctx context.Context, command string) ([]byte, error) {
+		for _, service := range []string{"app", "db"} {
+			if strings.HasSuffix(command, "ps -q "+service) {
+				mu.Lock()
+				defer mu.Unlock()
+				attempts[service]++
+				if attempts[service] < 3 {
+					return []byte("\n"), nil // still being created
+				}
+				return []byte(service + "-id\n"), nil
+			}
+		}
+		return nil, nil
+	})
+	provider.ContainerIDWait = 5 * time.Second
+
+	require.NoError(t, provider.Start(context.Background()))
+
+	assert.Equal(t, "app-id", provider.GetContainerID("app"))
+	assert.Equal(t, "db-id", provider.GetContainerID("db"))
+	assert.Len(t, runner.CallsContaining("ps -q app"), 3)
+}
+
+func TestStartContainerIDWaitBounded(t *testing.T) {
+	provider, runner := newTestProvider(t, testConfig(), nil)
+	provider.ContainerIDWait = 250 * time.Millisecond
+
+	start := time.Now()
+	require.NoError(t, provider.Start(context.Background()))
+
+	assert.Less(t, time.Since(start), 2*time.Second)
+	assert.Greater(t, len(runner.CallsContaining("ps -q app")), 1)
+	assert.Empty(t, provider.GetContainerID("app"))
+}
+
+func TestStartWithoutContainerIDWaitReadsOnce(t *testing.T) {
+	provider, runner := newTestProvider(t, testConfig(), nil)
+
+	require.NoError(t, provider.Start(context.Background()))
+
+	assert.Len(t, runner.CallsContaining("ps -q app"), 1)
+}
+
+func TestUpdateContainerIDsDropsRemovedContainers(t *testing.T) {
+	var mu sync.Mutex
+	migrateID := "migrate-id\n"
+	config := testConfig()
+	config.Services["migrate"] = ServiceConfig{ImageName: "app", Command: []string{"migrate"}}
+	provider, _ := newTestProvider(t, config, func(ctx context.Context, command string) ([]byte, error) {
+		switch {
+		case strings.HasSuffix(command, "ps -q app"):
+			return []byte("app-id\n"), nil
+		case strings.HasSuffix(command, "ps -q migrate"):
+			mu.Lock()
+			defer mu.Unlock()
+			return []byte(migrateID), nil
+		}
+		return nil, nil
+	})
+
+	require.NoError(t, provider.Start(context.Background()))
+	require.Equal(t, "migrate-id", provider.GetContainerID("migrate"))
+
+	// The one-shot finished and its container was removed
+	mu.Lock()
+	migrateID = ""
+	mu.Unlock()
+
+	statuses, err := provider.Status(context.Background())
+	require.NoError(t, err)
+
+	assert.Empty(t, provider.GetContainerID("migrate"))
+	assert.Equal(t, "not_found", statuses["migrate"])
+	assert.Equal(t, "app-id", provider.GetContainerID("app"))
+}
@@ -0,0 +1,129 @@
+// Package dockerapi provides a DockerProvider that reads container state and logs through
+// the Docker Engine API instead of parsing CLI output. It lives in its own module so the
+// Docker SDK stays out of the main package's dependencies. Importing it registers the
+// thirdpartyhosting.ProviderAPI kind with thirdpartyhosting.NewProvider.
+package dockerapi
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+
+	thirdpartyhosting "github.com/nimsforest/nimsforestdocker"
+)
+
+func init() {
+	thirdpartyhosting.RegisterProvider(thirdpartyhosting.ProviderAPI, func() (thirdpartyhosting.DockerProvider, error) {
+		provider, err := NewProvider()
+		if err != nil {
+			return nil, err
+		}
+		return provider, nil
+	})
+}
+
+// engineClient is the part of the Docker Engine API client Provider uses
+type engineClient interface {
+	ContainerInspect(ctx context.Context, containerID string) (types.ContainerJSON, error)
+	ContainerLogs(ctx context.Context, containerID string, options container.LogsOptions) (io.ReadCloser, error)
+}
+
+// Provider implements DockerProvider with the Docker Engine API for container state and
+// logs, which returns structured data instead of CLI output to parse. It is the embedded
+// DockerComposeProvider's Inspector, so Status and everything built on it, such as
+// IsRunning, WaitForHealthy, StartIfNotRunning and StartWatchdog, go through the API.
+// Orchestration such as Start, Stop and finding a service's container still goes through
+// docker-compose, whose options apply as usual.
+type Provider struct {
+	*thirdpartyhosting.DockerComposeProvider
+
+	api engineClient
+}
+
+// NewProvider creates a provider talking to the Docker daemon configured by the
+// environment (DOCKER_HOST and friends), negotiating the API version
+func NewProvider() (*Provider, error) {
+	api, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create docker client: %w", err)
+	}
+
+	return newProvider(thirdpartyhosting.NewDockerComposeProvider(), api), nil
+}
+
+// newProvider wraps compose, making the API its Inspector
+func newProvider(compose *thirdpartyhosting.DockerComposeProvider, api engineClient) *Provider {
+	p := &Provider{DockerComposeProvider: compose, api: api}
+	p.Inspector = p
+	return p
+}
+
+// InspectContainer returns a container's state as read through the API; it implements
+// thirdpartyhosting.ContainerInspector
+func (p *Provider) InspectContainer(ctx context.Context, containerID string) (thirdpartyhosting.ContainerState, error) {
+	inspected, err := p.api.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return thirdpartyhosting.ContainerState{}, fmt.Errorf("failed to inspect container %s: %w", containerID, err)
+	}
+	if inspected.ContainerJSONBase == nil || inspected.State == nil {
+		return thirdpartyhosting.ContainerState{}, fmt.Errorf("container %s has no state", containerID)
+	}
+
+	return containerState(inspected.State), nil
+}
+
+// GetLogs returns a service's stdout and stderr logs, read through the API
+func (p *Provider) GetLogs(ctx context.Context, serviceName string) (io.Reader, error) {
+	containerID, err := p.ResolveContainerID(ctx, serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.containerLogs(ctx, containerID)
+}
+
+// containerLogs reads a container's stdout and stderr logs
+func (p *Provider) containerLogs(ctx context.Context, containerID string) (io.Reader, error) {
+	inspected, err := p.api.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect container %s: %w", containerID, err)
+	}
+
+	stream, err := p.api.ContainerLogs(ctx, containerID, container.LogsOptions{ShowStdout: true, ShowStderr: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get logs: %w", err)
+	}
+	defer stream.Close()
+
+	// Without a TTY the daemon multiplexes stdout and stderr into one framed stream
+	var logs bytes.Buffer
+	if inspected.Config != nil && inspected.Config.Tty {
+		_, err = io.Copy(&logs, stream)
+	} else {
+		_, err = stdcopy.StdCopy(&logs, &logs, stream)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read logs: %w", err)
+	}
+
+	return &logs, nil
+}
+
+// containerState converts the API's container state to the form docker inspect decodes to
+func containerState(state *types.ContainerState) thirdpartyhosting.ContainerState {
+	converted := thirdpartyhosting.ContainerState{
+		Status:   state.Status,
+		Running:  state.Running,
+		ExitCode: state.ExitCode,
+	}
+	if state.Health != nil {
+		converted.Health = &thirdpartyhosting.ContainerHealth{Status: state.Health.Status, FailingStreak: state.Health.FailingStreak}
+	}
+	return converted
+}
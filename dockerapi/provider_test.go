@@ -0,0 +1,248 @@
+package dockerapi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	thirdpartyhosting "github.com/nimsforest/nimsforestdocker"
+)
+
+var (
+	_ thirdpartyhosting.DockerProvider     = &Provider{}
+	_ thirdpartyhosting.ContainerInspector = &Provider{}
+)
+
+// fakeEngine answers API calls from canned containers and log streams
+type fakeEngine struct {
+	containers map[string]types.ContainerJSON
+	logs       map[string][]byte
+	logOptions []container.LogsOptions
+}
+
+func (f *fakeEngine) ContainerInspect(ctx context.Context, containerID string) (types.ContainerJSON, error) {
+	inspected, ok := f.containers[containerID]
+	if !ok {
+		return types.ContainerJSON{}, notFoundError{containerID}
+	}
+	return inspected, nil
+}
+
+func (f *fakeEngine) ContainerLogs(ctx context.Context, containerID string, options container.LogsOptions) (io.ReadCloser, error) {
+	f.logOptions = append(f.logOptions, options)
+	return io.NopCloser(bytes.NewReader(f.logs[containerID])), nil
+}
+
+// notFoundError matches the SDK's errdefs not-found check
+type notFoundError struct{ id string }
+
+func (e notFoundError) Error() string { return "No such container: " + e.id }
+func (e notFoundError) NotFound()     {}
+
+// failingEngine fails every call, as when the daemon is unreachable
+type failingEngine struct{}
+
+func (failingEngine) ContainerInspect(ctx context.Context, containerID string) (types.ContainerJSON, error) {
+	return types.ContainerJSON{}, errors.New("Cannot connect to the Docker daemon")
+}
+
+func (failingEngine) ContainerLogs(ctx context.Context, containerID string, options container.LogsOptions) (io.ReadCloser, error) {
+	return nil, errors.New("Cannot connect to the Docker daemon")
+}
+
+// apiContainer builds an inspect response with the given state
+func apiContainer(id string, state *types.ContainerState, tty bool) types.ContainerJSON {
+	return types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{ID: id, State: state},
+		Config:            &container.Config{Tty: tty},
+	}
+}
+
+// fakeCompose puts a docker-compose on PATH that answers ps -q <service> with
+// "id-<service>", for each of services, and prints nothing for any other command
+func fakeCompose(t *testing.T, services ...string) {
+	t.Helper()
+
+	script := "#!/bin/sh\nfor last; do :; done\ncase \"$*\" in *\" ps -q \"*)\n\tcase \" " + strings.Join(services, " ") + " \" in *\" $last \"*) echo \"id-$last\" ;; esac ;;\nesac\n"
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "docker-compose"), []byte(script), 0755))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+// newTestProvider returns an initialized provider reading containers from engine, whose
+// docker-compose reports an "id-<service>" container for each of services
+func newTestProvider(t *testing.T, config thirdpartyhosting.ComposeConfig, engine engineClient, services ...string) *Provider {
+	t.Helper()
+
+	fakeCompose(t, services...)
+	provider := newProvider(thirdpartyhosting.NewDockerComposeProvider(), engine)
+	require.NoError(t, provider.Initialize(context.Background(), config))
+	return provider
+}
+
+// testConfig returns a small two-service configuration
+func testConfig() thirdpartyhosting.ComposeConfig {
+	return thirdpartyhosting.ComposeConfig{
+		ProjectName: "test-project",
+		Services: map[string]thirdpartyhosting.ServiceConfig{
+			"app": {ImageName: "app", ImageTag: "latest", DependsOn: []string{"db"}},
+			"db":  {ImageName: "postgres", ImageTag: "13"},
+		},
+	}
+}
+
+func TestNewProviderIsInspector(t *testing.T) {
+	provider := newProvider(thirdpartyhosting.NewDockerComposeProvider(), &fakeEngine{})
+
+	assert.Same(t, provider, provider.Inspector)
+}
+
+func TestRegisteredWithNewProvider(t *testing.T) {
+	provider, err := thirdpartyhosting.NewProvider(thirdpartyhosting.ProviderAPI)
+
+	require.NoError(t, err)
+	assert.IsType(t, &Provider{}, provider)
+}
+
+func TestStatus(t *testing.T) {
+	config := testConfig()
+	config.Services["cache"] = thirdpartyhosting.ServiceConfig{ImageName: "redis"}
+	config.Services["worker"] = thirdpartyhosting.ServiceConfig{ImageName: "worker"}
+	config.Services["debug"] = thirdpartyhosting.ServiceConfig{ImageName: "busybox", Profiles: []string{"debug"}}
+
+	engine := &fakeEngine{containers: map[string]types.ContainerJSON{
+		"id-app": apiContainer("id-app", &types.ContainerState{Status: "running", Running: true,
+			Health: &types.Health{Status: "healthy"}}, false),
+		"id-db":     apiContainer("id-db", &types.ContainerState{Status: "running", Running: true}, false),
+		"id-worker": apiContainer("id-worker", &types.ContainerState{Status: "exited", ExitCode: 1}, false),
+	}}
+	provider := newTestProvider(t, config, engine, "app", "db", "worker", "cache")
+
+	statuses, err := provider.Status(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"app":    "healthy",
+		"db":     "running",
+		"worker": "exited",
+		"cache":  "error", // docker-compose knows the container, the API doesn't
+		"debug":  "disabled",
+	}, statuses)
+}
+
+func TestStatusAPIError(t *testing.T) {
+	provider := newTestProvider(t, testConfig(), failingEngine{}, "app", "db")
+
+	statuses, err := provider.Status(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"app": "error", "db": "error"}, statuses)
+
+	running, err := provider.IsRunning(context.Background())
+	require.NoError(t, err)
+	assert.False(t, running)
+}
+
+func TestGetLogs(t *testing.T) {
+	var multiplexed bytes.Buffer
+	stdcopy.NewStdWriter(&multiplexed, stdcopy.Stdout).Write([]byte("listening on :3000\n"))
+
+	engine := &fakeEngine{
+		containers: map[string]types.ContainerJSON{
+			"id-app": apiContainer("id-app", &types.ContainerState{Status: "running", Running: true}, false),
+		},
+		logs: map[string][]byte{"id-app": multiplexed.Bytes()},
+	}
+	provider := newTestProvider(t, testConfig(), engine, "app")
+
+	reader, err := provider.GetLogs(context.Background(), "app")
+	require.NoError(t, err)
+	logs, _ := io.ReadAll(reader)
+	assert.Equal(t, "listening on :3000\n", string(logs))
+
+	// db has no container
+	_, err = provider.GetLogs(context.Background(), "db")
+	assert.ErrorContains(t, err, "container for service db not found")
+
+	_, err = provider.GetLogs(context.Background(), "cache")
+	assert.ErrorContains(t, err, "service cache not found")
+}
+
+func TestInspectContainer(t *testing.T) {
+	engine := &fakeEngine{containers: map[string]types.ContainerJSON{
+		"id-app": apiContainer("id-app", &types.ContainerState{Status: "running", Running: true,
+			Health: &types.Health{Status: "healthy", FailingStreak: 0}}, false),
+		"id-worker": apiContainer("id-worker", &types.ContainerState{Status: "exited", ExitCode: 1}, false),
+	}}
+	provider := newProvider(thirdpartyhosting.NewDockerComposeProvider(), engine)
+
+	state, err := provider.InspectContainer(context.Background(), "id-app")
+	require.NoError(t, err)
+	assert.Equal(t, thirdpartyhosting.ContainerState{Status: "running", Running: true,
+		Health: &thirdpartyhosting.ContainerHealth{Status: "healthy"}}, state)
+
+	state, err = provider.InspectContainer(context.Background(), "id-worker")
+	require.NoError(t, err)
+	assert.Equal(t, thirdpartyhosting.ContainerState{Status: "exited", ExitCode: 1}, state)
+
+	_, err = provider.InspectContainer(context.Background(), "id-gone")
+	assert.Error(t, err)
+}
+
+func TestInspectContainerAPIError(t *testing.T) {
+	provider := newProvider(thirdpartyhosting.NewDockerComposeProvider(), failingEngine{})
+
+	_, err := provider.InspectContainer(context.Background(), "id-app")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Cannot connect to the Docker daemon")
+}
+
+func TestContainerLogs(t *testing.T) {
+	var multiplexed bytes.Buffer
+	stdcopy.NewStdWriter(&multiplexed, stdcopy.Stdout).Write([]byte("listening on :3000\n"))
+	stdcopy.NewStdWriter(&multiplexed, stdcopy.Stderr).Write([]byte("warning: no cache\n"))
+
+	engine := &fakeEngine{
+		containers: map[string]types.ContainerJSON{
+			"id-app": apiContainer("id-app", &types.ContainerState{Status: "running", Running: true}, false),
+			"id-db":  apiContainer("id-db", &types.ContainerState{Status: "running", Running: true}, true),
+		},
+		logs: map[string][]byte{
+			"id-app": multiplexed.Bytes(),
+			"id-db":  []byte("psql> "),
+		},
+	}
+	provider := newProvider(thirdpartyhosting.NewDockerComposeProvider(), engine)
+
+	reader, err := provider.containerLogs(context.Background(), "id-app")
+	require.NoError(t, err)
+	logs, _ := io.ReadAll(reader)
+	assert.Equal(t, "listening on :3000\nwarning: no cache\n", string(logs))
+	assert.Equal(t, container.LogsOptions{ShowStdout: true, ShowStderr: true}, engine.logOptions[0])
+
+	// TTY containers aren't multiplexed
+	reader, err = provider.containerLogs(context.Background(), "id-db")
+	require.NoError(t, err)
+	logs, _ = io.ReadAll(reader)
+	assert.Equal(t, "psql> ", string(logs))
+}
+
+func TestGetLogsNotInitialized(t *testing.T) {
+	provider := newProvider(thirdpartyhosting.NewDockerComposeProvider(), &fakeEngine{})
+
+	_, err := provider.GetLogs(context.Background(), "app")
+
+	assert.Error(t, err)
+}
@@ -3,6 +3,7 @@ package thirdpartyhosting
 import (
 	"context"
 	"io"
+	"time"
 )
 
 // ServiceConfig contains configuration for a single Docker service
@@ -14,33 +15,233 @@ type ServiceConfig struct {
 	Environment  map[string]string
 	Volumes      []VolumeMapping
 
+	// VolumesFrom mounts all volumes of other services, e.g. ["data:ro"], or of external
+	// containers with a "container:" prefix; deprecated in favour of shared named volumes
+	VolumesFrom []string
+
+	// EnvPassthrough names variables passed through from the environment docker-compose
+	// runs in, rendered as a bare "KEY" entry; Environment entries take precedence
+	EnvPassthrough []string
+
+	// Entrypoint and Command override the image defaults. A nil slice leaves the image
+	// default alone, while an empty non-nil slice clears it, e.g. Entrypoint: []string{}
+	Entrypoint []string
+	Command    []string
+
+	// EntrypointShell and CommandShell give the override as a single string, e.g.
+	// "npm start", rendered as a scalar that compose splits itself; each is exclusive
+	// with its list form
+	EntrypointShell string
+	CommandShell    string
+
+	// Interactive containers such as shells and REPLs keep stdin open and allocate a TTY
+	StdinOpen bool
+	TTY       bool
+
+	// Extends names another service in the same config whose settings this one inherits,
+	// with its own settings merged on top as MergeConfigs does; chains are followed
+	Extends string
+
 	// Dependencies
 	DependsOn []string // e.g., Fider depends on "db"
 
+	// Long-form dependencies keyed by service name; DependsOn entries may be combined with them
+	Dependencies map[string]Dependency
+
+	// Links is the deprecated compose links directive, e.g. ["db", "db:database"];
+	// kept for older compose files, networks should be used instead
+	Links []string
+
+	// ExternalLinks connects to containers outside the project, e.g. ["shared-postgres:db"]
+	ExternalLinks []string
+
+	// Profiles this service belongs to; a service with no profiles is always active
+	Profiles []string // e.g., ["debug"]
+
 	// Restart policy
-	RestartPolicy string // e.g., "always"
+	RestartPolicy string // e.g., "always"; takes precedence over Deploy.RestartPolicy outside swarm mode
+
+	// Swarm-style deploy settings beyond resource limits
+	Deploy *DeployConfig
 
 	// Resource constraints
 	Resources ResourceLimits
+
+	// Block I/O weight and per-device throttling, for storage-sensitive workloads
+	BlkioConfig *BlkioConfig
+
+	// Health probing
+	HealthCheck *HealthCheck
+
+	// DisableHealthCheck turns off any healthcheck, including one built into the image.
+	// It takes precedence over HealthCheck.
+	DisableHealthCheck bool
+
+	// Build instructions; when set, ImageName (if any) names the built image
+	Build *BuildConfig
+
+	// Develop configures compose watch for development workflows
+	Develop *DevelopConfig
+
+	// PullPolicy controls when compose pulls the image: "always", "never", "missing" or "build"
+	PullPolicy string
+
+	// Platform pins the image platform pulled or built for this service, e.g. "linux/arm64";
+	// it overrides ComposeConfig.Platform
+	Platform string
+
+	// Control groups
+	CgroupParent string // e.g., "/latency-critical"
+	Cgroup       string // cgroup namespace: "host" or "private"
+
+	// Runtime selects an alternate container runtime, e.g. "runsc" (gVisor) or "nvidia"
+	Runtime string
+
+	// Isolation is the Windows container isolation technology: "process", "hyperv" or "default"
+	Isolation string
+
+	// Networking
+	MacAddress string   // e.g., "02:42:ac:11:00:02"
+	DNSOpt     []string // resolver options, e.g. ["ndots:2", "timeout:3"]
+
+	// ReadOnlyRootFS mounts the container's root filesystem read-only; pair it with
+	// tmpfs or volume mounts for paths the service writes to
+	ReadOnlyRootFS bool
+
+	// Configs mounts top-level configs into the container
+	Configs []ConfigMount
+
+	// GroupAdd adds supplementary groups to the container user, by name or numeric GID,
+	// e.g. ["docker"] for services that talk to the mounted docker socket
+	GroupAdd []string
+
+	// SecurityOpt sets labels for the security modules, e.g. SecurityOptNoNewPrivileges
+	// or SecurityOptSeccomp("profile.json"); relative seccomp profiles resolve against BaseDir
+	SecurityOpt []string
+}
+
+// BuildConfig describes how to build a service's image from source
+type BuildConfig struct {
+	Context    string            // e.g., "./app"
+	Dockerfile string            // relative to Context; defaults to "Dockerfile"
+	Args       map[string]string // build-time variables
+	Target     string            // multi-stage build stage to stop at, e.g., "production"
+	CacheFrom  []string          // images to use as layer cache sources
 }
 
 // PortMapping defines how ports are mapped from host to container
 type PortMapping struct {
-	HostPort      int
+	HostPort      int // 0 lets Docker pick a random host port; see GetPublishedPorts
 	ContainerPort int
-	Protocol      string // "tcp" or "udp"
+	Protocol      string // "tcp", "udp" or "sctp"; defaults to "tcp"
 }
 
 // VolumeMapping defines how volumes are mapped
 type VolumeMapping struct {
-	HostPath      string // e.g., "/var/fider/pg_data"
+	HostPath      string // e.g., "/var/fider/pg_data", or a named volume such as "pg_data"
 	ContainerPath string // e.g., "/var/lib/postgresql/data"
+	ReadOnly      bool
+
+	// Advanced options; setting any of these renders the volume in long-form syntax
+	Type        string // "bind", "volume" or "tmpfs"; inferred from HostPath when empty
+	Propagation string // bind propagation, e.g., "rshared"
+	NoCopy      bool   // don't copy image content into a new named volume
+	Consistency string // e.g., "cached" or "delegated" (macOS)
+}
+
+// Dependency configures a long-form depends_on entry
+type Dependency struct {
+	Condition string // "service_started" (default), "service_healthy" or "service_completed_successfully"
+	Restart   bool   // restart this service when the dependency is restarted
+}
+
+// DevelopConfig holds a service's develop section
+type DevelopConfig struct {
+	Watch []WatchRule
+}
+
+// WatchRule tells compose watch how to react to changes under a host path
+type WatchRule struct {
+	Path   string   // host path to watch; relative paths resolve against BaseDir
+	Action string   // "sync", "rebuild" or "sync+restart"
+	Target string   // container path files are synced to; required for sync actions
+	Ignore []string // patterns under Path to ignore, e.g. "node_modules/"
+}
+
+// ConfigFile is a top-level compose config, backed by either a file or inline content
+type ConfigFile struct {
+	File    string // path on the host; relative paths resolve against BaseDir
+	Content string // inline content, for small blobs defined in Go
+}
+
+// ConfigMount mounts a top-level config into a service
+type ConfigMount struct {
+	Source string // name of the config in ComposeConfig.Configs
+	Target string // path in the container; defaults to /<Source>
 }
 
 // ResourceLimits defines container resource constraints
 type ResourceLimits struct {
 	Memory   string // e.g., "512m"
 	CPUShare string // e.g., "0.5"
+
+	// Swap tuning, rendered as top-level service keys rather than under deploy
+	MemSwapLimit  string // e.g., "1g"; memory plus swap the container may use
+	MemSwappiness *int   // 0-100; nil leaves the host default
+
+	// CPU pinning, rendered as the top-level cpuset key
+	CPUSet string // e.g., "0-3" or "0,2"
+
+	// OOM killer controls for memory-critical services
+	OOMKillDisable bool
+	OOMScoreAdj    *int // -1000 to 1000; nil leaves the default
+}
+
+// BlkioConfig configures a service's blkio_config section
+type BlkioConfig struct {
+	Weight          int // relative weight, 10-1000; 0 leaves the default
+	DeviceReadBps   []BlkioDeviceRate
+	DeviceWriteBps  []BlkioDeviceRate
+	DeviceReadIOps  []BlkioDeviceRate
+	DeviceWriteIOps []BlkioDeviceRate
+}
+
+// BlkioDeviceRate limits I/O to a single device
+type BlkioDeviceRate struct {
+	Path string // e.g., "/dev/sdb"
+	Rate string // bytes per second for bps limits, e.g. "12mb"; operations per second for iops limits, e.g. "120"
+}
+
+// DeployConfig holds swarm-style deploy settings
+type DeployConfig struct {
+	RestartPolicy *DeployRestartPolicy
+	Replicas      *int // containers to run; nil leaves the default of one
+}
+
+// DeployRestartPolicy configures deploy.restart_policy
+type DeployRestartPolicy struct {
+	Condition   string        // "none", "on-failure" or "any"
+	Delay       time.Duration // wait between restart attempts
+	MaxAttempts int           // 0 means unlimited
+	Window      time.Duration // how long to wait before deciding a restart succeeded
+}
+
+// HealthCheck defines how Docker probes a container's health
+type HealthCheck struct {
+	// Test is the probe in exec form, e.g. ["curl", "-f", "http://localhost/health"], which
+	// is rendered with a "CMD" prefix. A list that already starts with "CMD", "CMD-SHELL" or
+	// "NONE" is rendered as is; ["NONE"] disables the image's healthcheck.
+	Test []string
+
+	// Shell is the probe in shell form, run with the container's default shell, e.g.
+	// "pg_isready -U postgres || exit 1"; it is rendered as ["CMD-SHELL", Shell] and is
+	// exclusive with Test
+	Shell string
+
+	Interval    time.Duration // time between probes
+	Timeout     time.Duration // time before a single probe is considered failed
+	Retries     int           // consecutive failures before the container is unhealthy
+	StartPeriod time.Duration // grace period before failures count
 }
 
 // ComposeConfig represents the configuration for multiple Docker services
@@ -48,9 +249,55 @@ type ComposeConfig struct {
 	Services map[string]ServiceConfig
 	Network  string
 
+	// NetworkLabels and VolumeLabels are set on the network and on every named volume the
+	// stack creates, so they can be found and removed by label; see Prune
+	NetworkLabels map[string]string
+	VolumeLabels  map[string]string
+
+	// Platform is the default platform for services that don't set their own, e.g. "linux/amd64"
+	Platform string
+
+	// Configs are the top-level configs services can mount, keyed by name
+	Configs map[string]ConfigFile
+
 	// Global settings
-	ProjectName string // Name for the compose project
-	EnvFile     string // Path to .env file if used
+	ProjectName    string   // Name for the compose project
+	EnvFile        string   // Path to .env file loaded into every service, relative to BaseDir; Environment entries take precedence
+	ActiveProfiles []string // Profiles enabled via --profile; services outside them are not started
+
+	// BaseDir is the directory relative bind-mount and build-context paths are resolved
+	// against, typically the directory of the compose file the config was loaded from.
+	// When empty, relative paths are resolved against the working directory.
+	BaseDir string
+
+	// InlineEnvFile reads EnvFile at render time and writes its merged values into each
+	// service's environment instead of referencing the file, for reproducible output
+	InlineEnvFile bool
+
+	// StrictVolumes makes ValidateConfig check that every bind-mount host path exists,
+	// instead of letting Docker create a missing one as an empty root-owned directory
+	StrictVolumes bool
+
+	// UseLongVolumeSyntax renders every volume in long form instead of "host:container"
+	UseLongVolumeSyntax bool
+
+	// RenderOptions adjusts the formatting of the generated compose file
+	RenderOptions RenderOptions
+
+	// ComposeVersion is the version key written to the compose file, e.g. "3.8". Empty
+	// uses the default of 3.4, or, once the provider's Validate has checked the binary, the
+	// versionless spec for Compose v2; ComposeSpecVersion omits the key for the versionless spec.
+	ComposeVersion string
+}
+
+// RenderOptions controls the formatting of generated compose files, e.g. to satisfy a YAML linter
+type RenderOptions struct {
+	Indent     int    // spaces per nesting level; defaults to 2
+	QuoteStyle string // "double" (default) or "single"; values that need escapes are always double-quoted
+
+	// UseAnchors writes environment and deploy blocks shared by several services once, as a
+	// YAML anchor on the first service, and as an alias everywhere else
+	UseAnchors bool
 }
 
 // DockerProvider defines the interface for Docker-based service hosting
@@ -59,6 +306,9 @@ type DockerProvider interface {
 	// ComposeConfig contains settings for all services that need to be run together
 	Initialize(ctx context.Context, config ComposeConfig) error
 
+	// Validate checks the initialized configuration and that the Docker tooling it needs is available
+	Validate(ctx context.Context) error
+
 	// Start creates and starts all Docker containers defined in the compose configuration
 	Start(ctx context.Context) error
 
@@ -66,7 +316,10 @@ type DockerProvider interface {
 	Stop(ctx context.Context) error
 
 	// Status returns the current status of all Docker containers
-	// Returns a map of service names to their status: "running", "stopped", "error", "not_found"
+	// Returns a map of service names to their status: "running", "stopped", "error", "not_found",
+	// or "disabled" for services whose profiles are not active. Running containers with a
+	// healthcheck report their health instead: "starting", "healthy" or "unhealthy".
+	// Other Docker states such as "exited", "created" or "paused" are passed through.
 	Status(ctx context.Context) (map[string]string, error)
 
 	// GetLogs retrieves Docker container logs for a specific service
@@ -20,6 +20,11 @@ func (m *MockDockerProvider) Initialize(ctx context.Context, config ComposeConfi
 	return args.Error(0)
 }
 
+func (m *MockDockerProvider) Validate(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
 func (m *MockDockerProvider) Start(ctx context.Context) error {
 	args := m.Called(ctx)
 	return args.Error(0)
@@ -79,6 +84,18 @@ func TestDockerProviderInitialize(t *testing.T) {
 	mockProvider.AssertExpectations(t)
 }
 
+func TestDockerProviderValidate(t *testing.T) {
+	mockProvider := new(MockDockerProvider)
+	ctx := context.Background()
+
+	mockProvider.On("Validate", ctx).Return(nil)
+
+	err := mockProvider.Validate(ctx)
+
+	assert.NoError(t, err)
+	mockProvider.AssertExpectations(t)
+}
+
 func TestDockerProviderStartAndStop(t *testing.T) {
 	mockProvider := new(MockDockerProvider)
 	ctx := context.Background()
@@ -0,0 +1,172 @@
+package thirdpartyhosting
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// envKeyPattern matches the variable names WriteEnvFile accepts
+var envKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// envPlainPattern matches values that can be written to a .env file without quotes
+var envPlainPattern = regexp.MustCompile(`^[A-Za-z0-9_./:@%+,=-]*$`)
+
+// envDoubleQuoteEscaper escapes a value for a double-quoted .env entry. "$" is escaped so
+// docker-compose doesn't interpolate it.
+var envDoubleQuoteEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`, `$`, `\$`)
+
+// envDoubleQuoteUnescaper reverses envDoubleQuoteEscaper
+var envDoubleQuoteUnescaper = strings.NewReplacer(`\\`, `\`, `\"`, `"`, `\n`, "\n", `\r`, "\r", `\t`, "\t", `\$`, `$`)
+
+// WriteEnvFile writes vars to a .env file at path as KEY=value lines sorted by key, and
+// points EnvFile at it. Values with spaces, quotes, "#", "$" or other special characters
+// are quoted: single quotes when that keeps them literal, otherwise double quotes with
+// backslash escapes.
+func (c *ComposeConfig) WriteEnvFile(path string, vars map[string]string) error {
+	keys := make([]string, 0, len(vars))
+	for key := range vars {
+		if !envKeyPattern.MatchString(key) {
+			return fmt.Errorf("invalid environment variable name %q", key)
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for _, key := range keys {
+		sb.WriteString(key + "=" + quoteEnvValue(vars[key]) + "\n")
+	}
+	if err := os.WriteFile(path, []byte(sb.String()), 0600); err != nil {
+		return fmt.Errorf("failed to write env file: %w", err)
+	}
+
+	c.EnvFile = path
+	return nil
+}
+
+// quoteEnvValue quotes a value for a .env file where needed
+func quoteEnvValue(value string) string {
+	switch {
+	case envPlainPattern.MatchString(value):
+		return value
+	case !strings.ContainsAny(value, "'\n\r"):
+		return "'" + value + "'"
+	default:
+		return `"` + envDoubleQuoteEscaper.Replace(value) + `"`
+	}
+}
+
+// parseEnvFile reads KEY=value lines from a .env file. Blank lines and # comments
+// are skipped, an "export " prefix is allowed and matching surrounding quotes are stripped.
+func parseEnvFile(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open env file: %w", err)
+	}
+	defer file.Close()
+
+	env := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		parts := strings.SplitN(line, "=", 2)
+		key := strings.TrimSpace(parts[0])
+		if key == "" {
+			return nil, fmt.Errorf("env file %s line %d: missing variable name", path, lineNumber)
+		}
+
+		value := ""
+		if len(parts) == 2 {
+			value = unquoteEnvValue(strings.TrimSpace(parts[1]))
+		}
+		env[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read env file: %w", err)
+	}
+
+	return env, nil
+}
+
+// unquoteEnvValue strips matching single or double quotes around a value. Single-quoted
+// values are literal; double-quoted ones have their backslash escapes resolved.
+func unquoteEnvValue(value string) string {
+	if len(value) >= 2 {
+		if value[0] == '"' && value[len(value)-1] == '"' {
+			return envDoubleQuoteUnescaper.Replace(value[1 : len(value)-1])
+		}
+		if value[0] == '\'' && value[len(value)-1] == '\'' {
+			return value[1 : len(value)-1]
+		}
+	}
+	return value
+}
+
+// mergeEnv combines env file values with a service's explicit environment.
+// Following compose precedence, explicit values win over the file.
+func mergeEnv(fileEnv, environment map[string]string) map[string]string {
+	if len(fileEnv) == 0 {
+		return environment
+	}
+
+	merged := make(map[string]string, len(fileEnv)+len(environment))
+	for key, value := range fileEnv {
+		merged[key] = value
+	}
+	for key, value := range environment {
+		merged[key] = value
+	}
+	return merged
+}
+
+// EffectiveEnv returns the environment a service's container will see from the compose
+// file: EnvFile values, overridden by Environment, plus passthrough variables that are set
+// in the environment docker-compose runs with, see InheritEnv and ExtraEnv. Variables baked
+// into the image are not included.
+func (p *DockerComposeProvider) EffectiveEnv(serviceName string) (map[string]string, error) {
+	p.mu.RLock()
+	if !p.initialized {
+		p.mu.RUnlock()
+		return nil, fmt.Errorf("provider not initialized")
+	}
+	config := p.config
+	p.mu.RUnlock()
+
+	service, exists := config.Services[serviceName]
+	if !exists {
+		return nil, fmt.Errorf("service %s not found", serviceName)
+	}
+
+	env := make(map[string]string)
+	if config.EnvFile != "" {
+		fileEnv, err := parseEnvFile(resolveRelativePath(config.BaseDir, config.EnvFile))
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range fileEnv {
+			env[key] = value
+		}
+	}
+
+	// Passthrough values come from the environment docker-compose runs in and, like any
+	// environment entry, win over the env file; an unset one leaves the file's value
+	for _, key := range passthroughKeys(service) {
+		if value, ok := p.lookupCommandEnv(key); ok {
+			env[key] = value
+		}
+	}
+	for key, value := range service.Environment {
+		env[key] = value
+	}
+
+	return env, nil
+}
@@ -0,0 +1,257 @@
+package thirdpartyhosting
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestEnvFile writes content to a .env file in a temporary directory
+func writeTestEnvFile(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), ".env")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestParseEnvFile(t *testing.T) {
+	path := writeTestEnvFile(t, "# database\nDB_HOST=db\nexport DB_PORT=5432\n\nDB_NAME=\"fider\"\nEMPTY=\n")
+
+	env, err := parseEnvFile(path)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"DB_HOST": "db",
+		"DB_PORT": "5432",
+		"DB_NAME": "fider",
+		"EMPTY":   "",
+	}, env)
+}
+
+func TestEnvironmentMapOverridesEnvFile(t *testing.T) {
+	path := writeTestEnvFile(t, "LOG_LEVEL=info\nDB_HOST=db\n")
+	config := ComposeConfig{
+		EnvFile:       path,
+		InlineEnvFile: true,
+		Services: map[string]ServiceConfig{
+			"app": {
+				ImageName:   "app",
+				Environment: map[string]string{"LOG_LEVEL": "debug"},
+			},
+		},
+	}
+
+	content, err := generateComposeContent(config)
+
+	assert.NoError(t, err)
+	assert.Contains(t, content, "    environment:\n"+
+		"      - DB_HOST=db\n"+
+		"      - LOG_LEVEL=debug\n")
+	assert.NotContains(t, content, "LOG_LEVEL=info")
+	assert.NotContains(t, content, "env_file:")
+}
+
+func TestEnvFileReferencedWhenNotInlined(t *testing.T) {
+	config := ComposeConfig{
+		EnvFile: "/etc/app/.env",
+		Services: map[string]ServiceConfig{
+			"app": {
+				ImageName:   "app",
+				Environment: map[string]string{"LOG_LEVEL": "debug"},
+			},
+		},
+	}
+
+	content, err := generateComposeContent(config)
+
+	assert.NoError(t, err)
+	assert.Contains(t, content, "    env_file:\n      - /etc/app/.env\n")
+	assert.Contains(t, content, "      - LOG_LEVEL=debug\n")
+}
+
+func TestInlineEnvFileMissing(t *testing.T) {
+	config := ComposeConfig{
+		EnvFile:       filepath.Join(t.TempDir(), "missing.env"),
+		InlineEnvFile: true,
+		Services:      map[string]ServiceConfig{"app": {ImageName: "app"}},
+	}
+
+	_, err := generateComposeContent(config)
+
+	assert.Error(t, err)
+}
+
+func TestRelativeEnvFileResolvedAgainstBaseDir(t *testing.T) {
+	config := ComposeConfig{
+		EnvFile:  ".env",
+		BaseDir:  "/srv/stack",
+		Services: map[string]ServiceConfig{"app": {ImageName: "app"}},
+	}
+
+	content, err := generateComposeContent(config)
+
+	assert.NoError(t, err)
+	assert.Contains(t, content, "    env_file:\n      - /srv/stack/.env\n")
+}
+
+func TestRelativeEnvFileResolvedAgainstWorkingDirectory(t *testing.T) {
+	config := ComposeConfig{
+		EnvFile:  "config/app.env",
+		Services: map[string]ServiceConfig{"app": {ImageName: "app"}},
+	}
+
+	content, err := generateComposeContent(config)
+
+	wd, _ := os.Getwd()
+	assert.NoError(t, err)
+	assert.Contains(t, content, "      - "+filepath.Join(wd, "config/app.env")+"\n")
+}
+
+func TestRelativeEnvFileInlinedFromBaseDir(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".env"), []byte("DB_HOST=db\n"), 0644))
+	config := ComposeConfig{
+		EnvFile:       ".env",
+		BaseDir:       dir,
+		InlineEnvFile: true,
+		Services:      map[string]ServiceConfig{"app": {ImageName: "app"}},
+	}
+
+	content, err := generateComposeContent(config)
+
+	require.NoError(t, err)
+	assert.Contains(t, content, "      - DB_HOST=db\n")
+}
+
+func TestEffectiveEnvPrecedence(t *testing.T) {
+	t.Setenv("SECRET_KEY", "from-host")
+	t.Setenv("LOG_LEVEL", "from-host")
+	path := writeTestEnvFile(t, "LOG_LEVEL=info\nDB_HOST=db\nSECRET_KEY=from-file\nREGION=eu\n")
+
+	config := ComposeConfig{
+		EnvFile: path,
+		Services: map[string]ServiceConfig{
+			"app": {
+				ImageName:      "app",
+				Environment:    map[string]string{"LOG_LEVEL": "debug", "PORT": "3000"},
+				EnvPassthrough: []string{"SECRET_KEY", "LOG_LEVEL", "UNSET_IN_HOST", "REGION_UNSET"},
+			},
+		},
+	}
+	provider, _ := newTestProvider(t, config, nil)
+
+	env, err := provider.EffectiveEnv("app")
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"LOG_LEVEL":  "debug",     // Environment beats passthrough and the file
+		"PORT":       "3000",      // Environment only
+		"SECRET_KEY": "from-host", // passthrough beats the file
+		"DB_HOST":    "db",        // file only
+		"REGION":     "eu",        // file only
+	}, env)
+}
+
+func TestEffectiveEnvUsesCommandEnvironment(t *testing.T) {
+	t.Setenv("SECRET_KEY", "from-host")
+	t.Setenv("TOKEN", "from-host")
+	config := ComposeConfig{Services: map[string]ServiceConfig{
+		"app": {ImageName: "app", EnvPassthrough: []string{"SECRET_KEY", "TOKEN"}},
+	}}
+	provider, _ := newTestProvider(t, config, nil)
+	provider.InheritEnv = false
+	provider.ExtraEnv = map[string]string{"SECRET_KEY": "from-extra"}
+
+	env, err := provider.EffectiveEnv("app")
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"SECRET_KEY": "from-extra"}, env, "TOKEN is not inherited")
+
+	provider.InheritEnv = true
+	env, err = provider.EffectiveEnv("app")
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"SECRET_KEY": "from-extra", "TOKEN": "from-host"}, env)
+}
+
+func TestEffectiveEnvRelativeEnvFile(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".env"), []byte("DB_HOST=db\n"), 0644))
+	config := ComposeConfig{
+		EnvFile:  ".env",
+		BaseDir:  dir,
+		Services: map[string]ServiceConfig{"app": {ImageName: "app"}},
+	}
+	provider, _ := newTestProvider(t, config, nil)
+
+	env, err := provider.EffectiveEnv("app")
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"DB_HOST": "db"}, env)
+}
+
+func TestEffectiveEnvErrors(t *testing.T) {
+	_, err := NewDockerComposeProvider().EffectiveEnv("app")
+	assert.Error(t, err)
+
+	provider, _ := newTestProvider(t, testConfig(), nil)
+	_, err = provider.EffectiveEnv("cache")
+	assert.Error(t, err)
+}
+
+func TestWriteEnvFileSortedOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	var config ComposeConfig
+
+	require.NoError(t, config.WriteEnvFile(path, map[string]string{
+		"ZONE":    "eu-west-1",
+		"DB_HOST": "db",
+		"API_URL": "https://example.com/v1?a=b",
+		"EMPTY":   "",
+	}))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "API_URL='https://example.com/v1?a=b'\nDB_HOST=db\nEMPTY=\nZONE=eu-west-1\n", string(content))
+	assert.Equal(t, path, config.EnvFile)
+}
+
+func TestWriteEnvFileEscaping(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	vars := map[string]string{
+		"SPACES":    "  hello world  ",
+		"COMMENT":   "value # not a comment",
+		"DOLLAR":    "pa$$word",
+		"QUOTE":     "it's \"quoted\"",
+		"MULTILINE": "line one\nline two",
+		"BACKSLASH": `C:\temp\'dir'`,
+	}
+	var config ComposeConfig
+
+	require.NoError(t, config.WriteEnvFile(path, vars))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "SPACES='  hello world  '\n")
+	assert.Contains(t, string(content), "DOLLAR='pa$$word'\n")
+	assert.Contains(t, string(content), `QUOTE="it's \"quoted\""`+"\n")
+	assert.Contains(t, string(content), `MULTILINE="line one\nline two"`+"\n")
+	assert.Contains(t, string(content), `BACKSLASH="C:\\temp\\'dir'"`+"\n")
+
+	parsed, err := parseEnvFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, vars, parsed)
+}
+
+func TestWriteEnvFileInvalidKey(t *testing.T) {
+	var config ComposeConfig
+
+	err := config.WriteEnvFile(filepath.Join(t.TempDir(), ".env"), map[string]string{"BAD KEY": "x"})
+
+	assert.Error(t, err)
+	assert.Empty(t, config.EnvFile)
+}
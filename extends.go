@@ -0,0 +1,60 @@
+package thirdpartyhosting
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// resolveExtends returns a copy of config in which every service that extends another
+// has been merged on top of it with mergeService, following chains of extends, and has
+// Extends cleared. It fails on an unknown service or a cycle, naming the services in it.
+func resolveExtends(config ComposeConfig) (ComposeConfig, error) {
+	resolved := make(map[string]ServiceConfig, len(config.Services))
+
+	var resolve func(name string, chain []string) (ServiceConfig, error)
+	resolve = func(name string, chain []string) (ServiceConfig, error) {
+		if service, done := resolved[name]; done {
+			return service, nil
+		}
+		for i, visited := range chain {
+			if visited == name {
+				cycle := append(append([]string{}, chain[i:]...), name)
+				return ServiceConfig{}, fmt.Errorf("extends cycle: %s", strings.Join(cycle, " -> "))
+			}
+		}
+
+		service := config.Services[name].clone()
+		if service.Extends != "" {
+			if _, exists := config.Services[service.Extends]; !exists {
+				return ServiceConfig{}, fmt.Errorf("service %s: extends unknown service %s", name, service.Extends)
+			}
+
+			parent, err := resolve(service.Extends, append(chain, name))
+			if err != nil {
+				return ServiceConfig{}, err
+			}
+			service = mergeService(parent.clone(), service)
+			service.Extends = ""
+		}
+
+		resolved[name] = service
+		return service, nil
+	}
+
+	names := make([]string, 0, len(config.Services))
+	for name := range config.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, err := resolve(name, nil); err != nil {
+			return ComposeConfig{}, err
+		}
+	}
+
+	if config.Services != nil {
+		config.Services = resolved
+	}
+	return config, nil
+}
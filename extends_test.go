@@ -0,0 +1,84 @@
+package thirdpartyhosting
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveExtends(t *testing.T) {
+	config := ComposeConfig{Services: map[string]ServiceConfig{
+		"base":   {ImageName: "app", ImageTag: "1.0", Environment: map[string]string{"MODE": "prod", "LOG": "info"}},
+		"worker": {Extends: "base", Command: []string{"work"}, Environment: map[string]string{"LOG": "debug"}},
+		"cron":   {Extends: "worker", Command: []string{"cron"}},
+	}}
+
+	resolved, err := resolveExtends(config)
+
+	require.NoError(t, err)
+	cron := resolved.Services["cron"]
+	assert.Equal(t, "app", cron.ImageName)
+	assert.Equal(t, []string{"cron"}, cron.Command)
+	assert.Equal(t, map[string]string{"MODE": "prod", "LOG": "debug"}, cron.Environment)
+	assert.Empty(t, cron.Extends)
+	assert.Equal(t, map[string]string{"MODE": "prod", "LOG": "info"}, config.Services["base"].Environment, "the original config must be left alone")
+}
+
+func TestResolveExtendsCycle(t *testing.T) {
+	config := ComposeConfig{Services: map[string]ServiceConfig{
+		"a": {ImageName: "app", Extends: "b"},
+		"b": {ImageName: "app", Extends: "a"},
+	}}
+
+	_, err := resolveExtends(config)
+	assert.EqualError(t, err, "extends cycle: a -> b -> a")
+
+	_, err = RenderComposeStruct(config)
+	assert.EqualError(t, err, "extends cycle: a -> b -> a")
+
+	assert.Error(t, NewDockerComposeProvider().Initialize(context.Background(), config))
+}
+
+func TestResolveExtendsSelfAndUnknown(t *testing.T) {
+	_, err := resolveExtends(ComposeConfig{Services: map[string]ServiceConfig{
+		"a": {ImageName: "app", Extends: "a"},
+	}})
+	assert.EqualError(t, err, "extends cycle: a -> a")
+
+	_, err = resolveExtends(ComposeConfig{Services: map[string]ServiceConfig{
+		"a": {ImageName: "app", Extends: "missing"},
+	}})
+	assert.EqualError(t, err, "service a: extends unknown service missing")
+}
+
+func TestInitializeStoresResolvedExtends(t *testing.T) {
+	config := ComposeConfig{Services: map[string]ServiceConfig{
+		"base":   {ImageName: "app", Build: &BuildConfig{Context: "."}},
+		"worker": {Extends: "base", Command: []string{"work"}},
+	}}
+	provider, _ := newTestProvider(t, config, nil)
+
+	content, err := generateComposeContent(config)
+	require.NoError(t, err)
+	assert.Contains(t, content, "  worker:\n    image: app\n    build:\n")
+	assert.NotContains(t, content, "extends")
+
+	_, skipped, err := provider.PullImages(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"base", "worker"}, skipped, "worker inherits its build from base")
+}
+
+func TestResolveExtendsCommandForms(t *testing.T) {
+	config := ComposeConfig{Services: map[string]ServiceConfig{
+		"base":   {ImageName: "app", CommandShell: "npm start"},
+		"worker": {Extends: "base", Command: []string{"node", "worker.js"}},
+	}}
+
+	resolved, err := resolveExtends(config)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"node", "worker.js"}, resolved.Services["worker"].Command)
+	assert.Empty(t, resolved.Services["worker"].CommandShell)
+}
@@ -0,0 +1,61 @@
+package thirdpartyhosting
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Provider kinds accepted by NewProvider
+const (
+	ProviderCompose = "compose" // DockerComposeProvider, driving the docker-compose CLI
+	ProviderAPI     = "api"     // dockerapi.Provider, using the Docker Engine API for status and logs
+)
+
+// providerPackages names the package to import for kinds implemented outside this one
+var providerPackages = map[string]string{
+	ProviderAPI: "github.com/nimsforest/nimsforestdocker/dockerapi",
+}
+
+var (
+	providersMu sync.RWMutex
+	providers   = make(map[string]func() (DockerProvider, error))
+)
+
+// RegisterProvider makes a provider kind available to NewProvider. Implementations that
+// live in their own package, with their own dependencies, call it from init, so importing
+// the package is enough, e.g. dockerapi registers ProviderAPI. It panics when kind is
+// already registered.
+func RegisterProvider(kind string, constructor func() (DockerProvider, error)) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+
+	if _, exists := providers[kind]; exists || kind == "" || kind == ProviderCompose {
+		panic(fmt.Sprintf("provider kind %q is already registered", kind))
+	}
+	providers[kind] = constructor
+}
+
+// NewProvider returns the DockerProvider implementation for kind. An empty kind selects
+// ProviderCompose; other kinds must have been registered with RegisterProvider.
+func NewProvider(kind string) (DockerProvider, error) {
+	if kind == "" || kind == ProviderCompose {
+		return NewDockerComposeProvider(), nil
+	}
+
+	providersMu.RLock()
+	constructor, exists := providers[kind]
+	providersMu.RUnlock()
+
+	if !exists {
+		if pkg, known := providerPackages[kind]; known {
+			return nil, fmt.Errorf("provider kind %q is not registered: import %s", kind, pkg)
+		}
+		return nil, fmt.Errorf("unknown provider kind %q", kind)
+	}
+
+	provider, err := constructor()
+	if err != nil {
+		return nil, err
+	}
+	return provider, nil
+}
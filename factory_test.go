@@ -0,0 +1,49 @@
+package thirdpartyhosting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewProvider(t *testing.T) {
+	for _, kind := range []string{"", ProviderCompose} {
+		provider, err := NewProvider(kind)
+
+		require.NoError(t, err, kind)
+		assert.IsType(t, &DockerComposeProvider{}, provider, kind)
+	}
+}
+
+func TestNewProviderUnregisteredKind(t *testing.T) {
+	provider, err := NewProvider(ProviderAPI)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "github.com/nimsforest/nimsforestdocker/dockerapi")
+	assert.Nil(t, provider)
+}
+
+func TestRegisterProvider(t *testing.T) {
+	RegisterProvider("test", func() (DockerProvider, error) { return NewDockerComposeProvider(), nil })
+	t.Cleanup(func() {
+		providersMu.Lock()
+		delete(providers, "test")
+		providersMu.Unlock()
+	})
+
+	provider, err := NewProvider("test")
+	require.NoError(t, err)
+	assert.NotNil(t, provider)
+
+	assert.Panics(t, func() { RegisterProvider("test", nil) })
+	assert.Panics(t, func() { RegisterProvider(ProviderCompose, nil) })
+}
+
+func TestNewProviderUnknownKind(t *testing.T) {
+	provider, err := NewProvider("podman")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "podman")
+	assert.Nil(t, provider)
+}
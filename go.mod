@@ -0,0 +1,13 @@
+module github.com/nimsforest/nimsforestdocker
+
+go 1.22
+
+require (
+	github.com/stretchr/testify v1.12.1
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/stretchr/objx v0.5.3 // indirect
+	go.yaml.in/yaml/v3 v3.0.5 // indirect
+)
@@ -0,0 +1,147 @@
+package thirdpartyhosting
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// WaitForHealthy blocks until every service is healthy, or running if it has no healthcheck.
+// On timeout the error lists the last observed status of each service that wasn't ready.
+func (p *DockerComposeProvider) WaitForHealthy(ctx context.Context, timeout time.Duration) error {
+	p.mu.RLock()
+	if !p.initialized {
+		p.mu.RUnlock()
+		return fmt.Errorf("provider not initialized")
+	}
+	config := p.config
+	p.mu.RUnlock()
+
+	var services []string
+	for service, serviceConfig := range config.Services {
+		if isServiceActive(config, serviceConfig) {
+			services = append(services, service)
+		}
+	}
+
+	return p.waitForServices(ctx, config, services, timeout)
+}
+
+// waitForServices blocks until each of services is healthy, or running if it has no healthcheck
+func (p *DockerComposeProvider) waitForServices(ctx context.Context, config ComposeConfig, services []string, timeout time.Duration) error {
+	p.mu.RLock()
+	interval := p.pollInterval
+	p.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for {
+		pending, err := p.pendingServices(ctx, config, services)
+		if err != nil {
+			return err
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for services to become healthy: %s", describePending(pending))
+		case <-time.After(interval):
+		}
+	}
+}
+
+// StartAndWait starts all services and waits until they are healthy
+func (p *DockerComposeProvider) StartAndWait(ctx context.Context, timeout time.Duration) error {
+	if err := p.Start(ctx); err != nil {
+		return err
+	}
+
+	return p.WaitForHealthy(ctx, timeout)
+}
+
+// pendingServices returns which of services aren't ready yet, mapped to their current status
+func (p *DockerComposeProvider) pendingServices(ctx context.Context, config ComposeConfig, services []string) (map[string]string, error) {
+	if err := p.updateContainerIDs(ctx); err != nil {
+		return nil, err
+	}
+
+	var containerIDs []string
+	for _, service := range services {
+		containerIDs = append(containerIDs, p.GetContainerIDs(service)...)
+	}
+	states := p.inspectStates(ctx, containerIDs)
+
+	pending := make(map[string]string)
+	for _, service := range services {
+		serviceConfig := config.Services[service]
+		serviceContainerIDs := p.GetContainerIDs(service)
+		if len(serviceContainerIDs) == 0 {
+			pending[service] = "not_found"
+			continue
+		}
+
+		// A scaled service is ready only once all of its containers are
+		for _, containerID := range serviceContainerIDs {
+			state, ok := states[containerID]
+			if !ok {
+				pending[service] = "error"
+				break
+			}
+
+			status := state.healthStatus()
+			if status == "healthy" || (status == "running" && !hasHealthCheck(serviceConfig)) {
+				continue
+			}
+			pending[service] = status
+			break
+		}
+	}
+
+	return pending, nil
+}
+
+// describePending formats pending service statuses in a stable order
+func describePending(pending map[string]string) string {
+	services := make([]string, 0, len(pending))
+	for service := range pending {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+
+	parts := make([]string, len(services))
+	for i, service := range services {
+		parts[i] = fmt.Sprintf("%s: %s", service, pending[service])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// hasHealthCheck reports whether a service defines a healthcheck of its own that is not
+// disabled, either by DisableHealthCheck or by a ["NONE"] test
+func hasHealthCheck(service ServiceConfig) bool {
+	hc := service.HealthCheck
+	if hc == nil || service.DisableHealthCheck {
+		return false
+	}
+	return len(hc.Test) == 0 || hc.Test[0] != "NONE"
+}
+
+// IsRunning reports whether every active service is up, meaning Status reports it as
+// "running" or "healthy". Services outside the active profiles are not considered.
+func (p *DockerComposeProvider) IsRunning(ctx context.Context) (bool, error) {
+	statuses, err := p.Status(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	for _, status := range statuses {
+		if status != "running" && status != "healthy" && status != "disabled" {
+			return false, nil
+		}
+	}
+	return true, nil
+}
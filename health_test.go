@@ -0,0 +1,139 @@
+package thirdpartyhosting
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// healthHandler answers ps and inspect calls with one container per service. Statuses
+// "starting", "healthy" and "unhealthy" describe a running container with a healthcheck.
+func healthHandler(statuses map[string]string) func(ctx context.Context, command string) ([]byte, error) {
+	return func(ctx context.Context, command string) ([]byte, error) {
+		if strings.HasPrefix(command, "docker inspect --type container ") {
+			var entries []string
+			for _, id := range strings.Fields(strings.TrimPrefix(command, "docker inspect --type container ")) {
+				if status, ok := statuses[strings.TrimPrefix(id, "id-")]; ok {
+					entries = append(entries, inspectEntry(id, status))
+				}
+			}
+			return []byte("[" + strings.Join(entries, ",") + "]\n"), nil
+		}
+
+		for service, status := range statuses {
+			if strings.HasSuffix(command, "ps -q "+service) {
+				return []byte("id-" + service + "\n"), nil
+			}
+			if strings.Contains(command, "inspect") && strings.HasSuffix(command, " id-"+service) {
+				return []byte(stateJSON(status) + "\n"), nil
+			}
+		}
+		return nil, nil
+	}
+}
+
+// inspectEntry renders one element of the JSON array printed by a plain docker inspect
+func inspectEntry(id, status string) string {
+	return fmt.Sprintf(`{"Id":%q,"State":%s}`, id, stateJSON(status))
+}
+
+// stateJSON renders the docker inspect {{json .State}} output for a status
+func stateJSON(status string) string {
+	switch status {
+	case "starting", "healthy", "unhealthy":
+		return fmt.Sprintf(`{"Status":"running","Running":true,"ExitCode":0,"Health":{"Status":%q,"FailingStreak":0}}`, status)
+	case "running":
+		return `{"Status":"running","Running":true,"ExitCode":0}`
+	default:
+		return fmt.Sprintf(`{"Status":%q,"Running":false,"ExitCode":1}`, status)
+	}
+}
+
+func TestStartAndWaitHealthy(t *testing.T) {
+	config := testConfig()
+	app := config.Services["app"]
+	app.HealthCheck = &HealthCheck{Test: []string{"CMD", "true"}}
+	config.Services["app"] = app
+
+	provider, runner := newTestProvider(t, config, healthHandler(map[string]string{
+		"app": "healthy",
+		"db":  "running",
+	}))
+	provider.pollInterval = time.Millisecond
+
+	err := provider.StartAndWait(context.Background(), time.Second)
+
+	assert.NoError(t, err)
+	assert.Len(t, runner.CallsContaining("up -d"), 1)
+}
+
+func TestStartAndWaitHealthCheckNone(t *testing.T) {
+	config := testConfig()
+	app := config.Services["app"]
+	app.HealthCheck = &HealthCheck{Test: []string{"NONE"}}
+	config.Services["app"] = app
+
+	provider, _ := newTestProvider(t, config, healthHandler(map[string]string{
+		"app": "running",
+		"db":  "running",
+	}))
+	provider.pollInterval = time.Millisecond
+
+	assert.NoError(t, provider.StartAndWait(context.Background(), time.Second))
+}
+
+func TestStartAndWaitServiceNeverHealthy(t *testing.T) {
+	config := testConfig()
+	db := config.Services["db"]
+	db.HealthCheck = &HealthCheck{Test: []string{"CMD", "pg_isready"}}
+	config.Services["db"] = db
+
+	provider, _ := newTestProvider(t, config, healthHandler(map[string]string{
+		"app": "running",
+		"db":  "unhealthy",
+	}))
+	provider.pollInterval = time.Millisecond
+
+	err := provider.StartAndWait(context.Background(), 50*time.Millisecond)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "db: unhealthy")
+	assert.NotContains(t, err.Error(), "app:")
+}
+
+func TestIsRunning(t *testing.T) {
+	config := testConfig()
+	config.Services["debug"] = ServiceConfig{ImageName: "busybox", Profiles: []string{"debug"}}
+	provider, _ := newTestProvider(t, config, healthHandler(map[string]string{
+		"app": "healthy",
+		"db":  "running",
+	}))
+
+	running, err := provider.IsRunning(context.Background())
+
+	assert.NoError(t, err)
+	assert.True(t, running)
+}
+
+func TestIsRunningOneStopped(t *testing.T) {
+	provider, _ := newTestProvider(t, testConfig(), healthHandler(map[string]string{
+		"app": "running",
+		"db":  "exited",
+	}))
+
+	running, err := provider.IsRunning(context.Background())
+
+	assert.NoError(t, err)
+	assert.False(t, running)
+}
+
+func TestIsRunningNotInitialized(t *testing.T) {
+	running, err := NewDockerComposeProvider().IsRunning(context.Background())
+
+	assert.Error(t, err)
+	assert.False(t, running)
+}
@@ -0,0 +1,113 @@
+package thirdpartyhosting
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLifecycleHookOrdering(t *testing.T) {
+	var mu sync.Mutex
+	var events []string
+	record := func(event string) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+	}
+
+	provider, _ := newTestProvider(t, testConfig(), func(ctx context.Context, command string) ([]byte, error) {
+		switch {
+		case strings.Contains(command, "up -d"):
+			record("up")
+		case strings.HasSuffix(command, " down"):
+			record("down")
+		}
+		return nil, nil
+	})
+	provider.PreStart = func(ctx context.Context) error {
+		record("pre-start")
+		return nil
+	}
+	provider.PostStop = func(ctx context.Context) error {
+		record("post-stop")
+		return nil
+	}
+
+	assert.NoError(t, provider.Start(context.Background()))
+	assert.NoError(t, provider.Stop(context.Background()))
+
+	assert.Equal(t, []string{"pre-start", "up", "down", "post-stop"}, events)
+}
+
+func TestFailingPreStartAbortsStart(t *testing.T) {
+	provider, runner := newTestProvider(t, testConfig(), nil)
+	provider.PreStart = func(ctx context.Context) error {
+		return errors.New("network setup failed")
+	}
+
+	err := provider.Start(context.Background())
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "network setup failed")
+	assert.Empty(t, runner.Calls())
+}
+
+func TestFailingPostStopIsReturned(t *testing.T) {
+	provider, runner := newTestProvider(t, testConfig(), nil)
+	provider.PostStop = func(ctx context.Context) error {
+		return errors.New("cleanup failed")
+	}
+
+	err := provider.Stop(context.Background())
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "cleanup failed")
+	assert.Len(t, runner.CallsContaining(" down"), 1)
+}
+
+func TestOnOperation(t *testing.T) {
+	type operation struct {
+		op  string
+		dur time.Duration
+		err error
+	}
+	var ops []operation
+
+	provider, _ := newTestProvider(t, testConfig(), func(ctx context.Context, command string) ([]byte, error) {
+		switch {
+		case strings.HasSuffix(command, " up -d"):
+			time.Sleep(5 * time.Millisecond)
+		case strings.HasSuffix(command, " down"):
+			return []byte("network in use"), errors.New("exit status 1")
+		}
+		return nil, nil
+	})
+	provider.PullOnStart = true
+	provider.OnOperation = func(op string, dur time.Duration, err error) {
+		ops = append(ops, operation{op, dur, err})
+	}
+
+	assert.NoError(t, provider.Start(context.Background()))
+	_, err := provider.Status(context.Background())
+	assert.NoError(t, err)
+	assert.Error(t, provider.Stop(context.Background()))
+
+	require.Len(t, ops, 4)
+	assert.Equal(t, []string{"pull", "start", "status", "stop"}, []string{ops[0].op, ops[1].op, ops[2].op, ops[3].op})
+	assert.GreaterOrEqual(t, ops[1].dur, 5*time.Millisecond)
+	assert.GreaterOrEqual(t, ops[1].dur, ops[0].dur)
+	assert.NoError(t, ops[1].err)
+	assert.Error(t, ops[3].err)
+}
+
+func TestOnOperationNil(t *testing.T) {
+	provider, _ := newTestProvider(t, testConfig(), nil)
+
+	assert.NoError(t, provider.Start(context.Background()))
+}
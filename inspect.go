@@ -0,0 +1,163 @@
+package thirdpartyhosting
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// defaultInspectBatchSize is how many containers one docker inspect call covers when
+// InspectBatchSize is unset
+const defaultInspectBatchSize = 50
+
+// stateFormat asks docker inspect for the container state as JSON, which decodes
+// the same way across Docker versions and container states
+const stateFormat = "{{json .State}}"
+
+// ContainerState mirrors the parts of docker inspect's .State the provider reads.
+// Fields missing from the JSON keep their zero values.
+type ContainerState struct {
+	Status   string // e.g., "created", "running", "exited"
+	Running  bool
+	ExitCode int
+	Health   *ContainerHealth // nil when the container has no healthcheck
+}
+
+// ContainerHealth mirrors docker inspect's .State.Health
+type ContainerHealth struct {
+	Status        string // "starting", "healthy" or "unhealthy"
+	FailingStreak int
+}
+
+// ContainerInspector looks up a container's state in place of docker inspect, e.g. through
+// the Docker Engine API. Status, the readiness checks built on it such as IsRunning and
+// WaitForHealthy, and Snapshot all use it; a container it fails on reports "error".
+type ContainerInspector interface {
+	InspectContainer(ctx context.Context, containerID string) (ContainerState, error)
+}
+
+// inspectState runs docker inspect, or the Inspector, for a container and decodes its state
+func (p *DockerComposeProvider) inspectState(ctx context.Context, containerID string) (ContainerState, error) {
+	if p.Inspector != nil {
+		return p.Inspector.InspectContainer(ctx, containerID)
+	}
+
+	output, err := p.commands().Run(ctx, "docker", "inspect", "--format", stateFormat, containerID)
+	if err != nil {
+		return ContainerState{}, fmt.Errorf("failed to inspect container %s: %s, error: %w", containerID, string(output), err)
+	}
+
+	return parseContainerState(output)
+}
+
+// inspectStates inspects many containers with as few docker inspect calls as the batch
+// size allows, returning their states keyed by the given IDs. Containers that could not
+// be inspected are missing from the result.
+func (p *DockerComposeProvider) inspectStates(ctx context.Context, containerIDs []string) map[string]ContainerState {
+	states := make(map[string]ContainerState, len(containerIDs))
+	if p.Inspector != nil {
+		for _, id := range containerIDs {
+			if state, err := p.inspectState(ctx, id); err == nil {
+				states[id] = state
+			}
+		}
+		return states
+	}
+
+	batchSize := p.InspectBatchSize
+	if batchSize <= 0 {
+		batchSize = defaultInspectBatchSize
+	}
+
+	for start := 0; start < len(containerIDs); start += batchSize {
+		end := start + batchSize
+		if end > len(containerIDs) {
+			end = len(containerIDs)
+		}
+		batch := containerIDs[start:end]
+
+		args := append([]string{"inspect", "--type", "container"}, batch...)
+		output, err := p.commands().Run(ctx, "docker", args...)
+		if err == nil {
+			var inspected map[string]ContainerState
+			if inspected, err = parseInspectArray(output, batch); err == nil {
+				for id, state := range inspected {
+					states[id] = state
+				}
+				continue
+			}
+		}
+
+		// A container that vanished fails the whole call, so fall back to one at a time
+		for _, id := range batch {
+			if state, err := p.inspectState(ctx, id); err == nil {
+				states[id] = state
+			}
+		}
+	}
+
+	return states
+}
+
+// parseInspectArray decodes the JSON array printed by docker inspect for several containers
+// and returns the states keyed by whichever of containerIDs (full or short) names each one
+func parseInspectArray(output []byte, containerIDs []string) (map[string]ContainerState, error) {
+	var entries []struct {
+		ID    string `json:"Id"`
+		State json.RawMessage
+	}
+	if err := json.Unmarshal(output, &entries); err != nil {
+		return nil, fmt.Errorf("failed to decode docker inspect output: %w", err)
+	}
+
+	states := make(map[string]ContainerState, len(entries))
+	for _, entry := range entries {
+		state, err := parseContainerState(entry.State)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range containerIDs {
+			if strings.HasPrefix(entry.ID, id) {
+				states[id] = state
+			}
+		}
+	}
+
+	return states, nil
+}
+
+// parseContainerState decodes the output of docker inspect --format '{{json .State}}'
+func parseContainerState(output []byte) (ContainerState, error) {
+	var state ContainerState
+	if err := json.Unmarshal([]byte(strings.TrimSpace(string(output))), &state); err != nil {
+		return ContainerState{}, fmt.Errorf("failed to decode container state: %w", err)
+	}
+
+	if state.Status == "" {
+		if state.Running {
+			state.Status = "running"
+		} else {
+			state.Status = "stopped"
+		}
+	}
+
+	return state, nil
+}
+
+// serviceStatus returns the status reported by Status: the health status for a running
+// container with a healthcheck, and the plain state otherwise
+func (s ContainerState) serviceStatus() string {
+	if s.Running {
+		return s.healthStatus()
+	}
+	return s.Status
+}
+
+// healthStatus returns the health status when the container has a healthcheck, and the plain status otherwise
+func (s ContainerState) healthStatus() string {
+	if s.Health != nil && s.Health.Status != "" {
+		return s.Health.Status
+	}
+	return s.Status
+}
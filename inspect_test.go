@@ -0,0 +1,140 @@
+package thirdpartyhosting
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseContainerStateWithoutHealth(t *testing.T) {
+	state, err := parseContainerState([]byte(`{"Status":"running","Running":true,"Paused":false,"Pid":1234,"ExitCode":0}` + "\n"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "running", state.Status)
+	assert.Nil(t, state.Health)
+	assert.Equal(t, "running", state.healthStatus())
+}
+
+func TestParseContainerStateWithHealth(t *testing.T) {
+	state, err := parseContainerState([]byte(`{"Status":"running","Running":true,"Health":{"Status":"unhealthy","FailingStreak":3,"Log":[]}}`))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "running", state.Status)
+	assert.Equal(t, 3, state.Health.FailingStreak)
+	assert.Equal(t, "unhealthy", state.healthStatus())
+}
+
+func TestParseContainerStateMissingStatus(t *testing.T) {
+	running, err := parseContainerState([]byte(`{"Running":true}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "running", running.Status)
+
+	stopped, err := parseContainerState([]byte(`{"ExitCode":137}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "stopped", stopped.Status)
+	assert.Equal(t, 137, stopped.ExitCode)
+}
+
+func TestParseContainerStateInvalid(t *testing.T) {
+	_, err := parseContainerState([]byte("Template parsing error: template: :1: unexpected"))
+
+	assert.Error(t, err)
+}
+
+func TestParseInspectArray(t *testing.T) {
+	output := `[
+  {"Id": "0123456789abcdef0123", "Name": "/tiers-api-1", "State": {"Status": "running", "Running": true, "Health": {"Status": "healthy"}}},
+  {"Id": "fedcba98765432100000", "Name": "/tiers-db-1", "State": {"Status": "exited", "Running": false, "ExitCode": 2}}
+]`
+
+	states, err := parseInspectArray([]byte(output), []string{"0123456789ab", "fedcba9876543210"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "healthy", states["0123456789ab"].healthStatus())
+	assert.Equal(t, "exited", states["fedcba9876543210"].Status)
+	assert.Equal(t, 2, states["fedcba9876543210"].ExitCode)
+}
+
+func TestInspectStatesBatches(t *testing.T) {
+	config := ComposeConfig{ProjectName: "batch", Services: map[string]ServiceConfig{}}
+	statuses := make(map[string]string)
+	var ids []string
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		config.Services[name] = ServiceConfig{ImageName: name}
+		statuses[name] = "running"
+		ids = append(ids, "id-"+name)
+	}
+	provider, runner := newTestProvider(t, config, healthHandler(statuses))
+	provider.InspectBatchSize = 2
+
+	states := provider.inspectStates(context.Background(), ids)
+
+	assert.Len(t, states, 5)
+	assert.Equal(t, []string{
+		"docker inspect --type container id-a id-b",
+		"docker inspect --type container id-c id-d",
+		"docker inspect --type container id-e",
+	}, runner.CallsContaining("docker inspect"))
+}
+
+func TestInspectStatesFallsBackWhenContainerVanished(t *testing.T) {
+	statuses := healthHandler(map[string]string{"app": "running"})
+	provider, runner := newTestProvider(t, testConfig(), func(ctx context.Context, command string) ([]byte, error) {
+		// Both the batch and the single inspect of the vanished container fail
+		if strings.HasSuffix(command, " id-gone") {
+			return []byte("Error: No such object: id-gone\n"), errors.New("exit status 1")
+		}
+		return statuses(ctx, command)
+	})
+
+	states := provider.inspectStates(context.Background(), []string{"id-app", "id-gone"})
+
+	assert.Equal(t, "running", states["id-app"].Status)
+	assert.NotContains(t, states, "id-gone")
+	assert.Len(t, runner.CallsContaining("--format"), 2)
+}
+
+// fakeInspector answers from canned states and fails for any other container
+type fakeInspector map[string]ContainerState
+
+func (f fakeInspector) InspectContainer(ctx context.Context, containerID string) (ContainerState, error) {
+	if state, ok := f[containerID]; ok {
+		return state, nil
+	}
+	return ContainerState{}, errors.New("Cannot connect to the Docker daemon")
+}
+
+func TestInspectorReplacesDockerInspect(t *testing.T) {
+	provider, runner := newTestProvider(t, testConfig(), healthHandler(map[string]string{"app": "running", "db": "running"}))
+	provider.Inspector = fakeInspector{
+		"id-app": {Status: "running", Running: true, Health: &ContainerHealth{Status: "healthy"}},
+	}
+
+	statuses, err := provider.Status(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"app": "healthy", "db": "error"}, statuses, "a failed inspect marks only its service")
+
+	running, err := provider.IsRunning(context.Background())
+	require.NoError(t, err)
+	assert.False(t, running)
+
+	provider.Inspector.(fakeInspector)["id-db"] = ContainerState{Status: "running", Running: true}
+	running, err = provider.IsRunning(context.Background())
+	require.NoError(t, err)
+	assert.True(t, running)
+	assert.NoError(t, provider.WaitForHealthy(context.Background(), time.Second))
+
+	assert.Empty(t, runner.CallsContaining("docker inspect"))
+}
+
+func TestContainerStateServiceStatus(t *testing.T) {
+	exited, err := parseContainerState([]byte(`{"Status":"exited","Running":false,"Health":{"Status":"unhealthy"}}`))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "exited", exited.serviceStatus())
+}
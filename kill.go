@@ -0,0 +1,48 @@
+package thirdpartyhosting
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// defaultKillSignal is the signal Kill sends when none is given
+const defaultKillSignal = "SIGKILL"
+
+// killSignals lists the signal names Kill accepts, without the SIG prefix
+var killSignals = map[string]bool{
+	"HUP": true, "INT": true, "QUIT": true, "ABRT": true, "KILL": true, "USR1": true,
+	"USR2": true, "ALRM": true, "TERM": true, "CONT": true, "STOP": true, "TSTP": true,
+	"WINCH": true,
+}
+
+// Kill sends signal to every service's container with docker-compose kill, without the
+// graceful drain Stop gives them. An empty signal sends SIGKILL. Signals are named with or
+// without the SIG prefix, e.g. "SIGUSR1" or "USR1". Containers are left in place.
+func (p *DockerComposeProvider) Kill(ctx context.Context, signal string) error {
+	if signal == "" {
+		signal = defaultKillSignal
+	}
+	signal = strings.ToUpper(signal)
+	if !killSignals[strings.TrimPrefix(signal, "SIG")] {
+		return fmt.Errorf("unsupported signal %q", signal)
+	}
+	if !strings.HasPrefix(signal, "SIG") {
+		signal = "SIG" + signal
+	}
+
+	p.mu.RLock()
+	if !p.initialized {
+		p.mu.RUnlock()
+		return fmt.Errorf("provider not initialized")
+	}
+	config := p.config
+	p.mu.RUnlock()
+
+	output, err := p.runCompose(ctx, nil, composeArgs(config, "", "kill", "-s", signal)...)
+	if err != nil {
+		return fmt.Errorf("failed to send %s to containers: %s, error: %w", signal, string(output), err)
+	}
+
+	return nil
+}
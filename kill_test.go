@@ -0,0 +1,40 @@
+package thirdpartyhosting
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKillDefaultsToSIGKILL(t *testing.T) {
+	provider, runner := newTestProvider(t, testConfig(), nil)
+
+	require.NoError(t, provider.Kill(context.Background(), ""))
+
+	assert.Equal(t, []string{"docker-compose -p test-project kill -s SIGKILL"}, runner.Calls())
+	assert.Empty(t, runner.CallsContaining("down"))
+}
+
+func TestKillCustomSignal(t *testing.T) {
+	for _, signal := range []string{"SIGUSR1", "usr1"} {
+		provider, runner := newTestProvider(t, testConfig(), nil)
+
+		require.NoError(t, provider.Kill(context.Background(), signal))
+
+		assert.Equal(t, []string{"docker-compose -p test-project kill -s SIGUSR1"}, runner.Calls(), signal)
+	}
+}
+
+func TestKillRejectsUnknownSignal(t *testing.T) {
+	provider, runner := newTestProvider(t, testConfig(), nil)
+
+	assert.Error(t, provider.Kill(context.Background(), "SIGNOPE"))
+	assert.Error(t, provider.Kill(context.Background(), "9; rm -rf /"))
+	assert.Empty(t, runner.Calls())
+}
+
+func TestKillNotInitialized(t *testing.T) {
+	assert.Error(t, NewDockerComposeProvider().Kill(context.Background(), ""))
+}
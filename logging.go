@@ -0,0 +1,91 @@
+package thirdpartyhosting
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+// Logger receives the provider's internal debug logging as a message followed by
+// alternating keys and values. *slog.Logger satisfies it.
+type Logger interface {
+	Debug(msg string, args ...interface{})
+}
+
+// loggingRunner logs every command a commandRunner runs, with its duration and exit status
+type loggingRunner struct {
+	runner commandRunner
+	logger Logger
+}
+
+// Run executes the command through the wrapped runner and logs it
+func (r loggingRunner) Run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	start := time.Now()
+	output, err := r.runner.Run(ctx, name, args...)
+	r.log(start, err, name, args)
+	return output, err
+}
+
+// Stream executes the command through the wrapped runner and logs it
+func (r loggingRunner) Stream(ctx context.Context, stdin io.Reader, output io.Writer, name string, args ...string) error {
+	start := time.Now()
+	err := r.runner.Stream(ctx, stdin, output, name, args...)
+	r.log(start, err, name, args)
+	return err
+}
+
+// log writes the debug entry for a finished command
+func (r loggingRunner) log(start time.Time, err error, name string, args []string) {
+	fields := []interface{}{
+		"command", strings.Join(append([]string{name}, redactEnvValues(args)...), " "),
+		"duration", time.Since(start),
+		"exit_code", exitStatus(err),
+	}
+	if err != nil {
+		fields = append(fields, "error", err)
+	}
+	r.logger.Debug("docker command finished", fields...)
+}
+
+// redactEnvValues returns a copy of args in which the KEY=value after each -e or --env
+// flag is reduced to KEY, so values such as RunOneShot's secrets stay out of the log
+func redactEnvValues(args []string) []string {
+	redacted := make([]string, len(args))
+	copy(redacted, args)
+	for i := 1; i < len(redacted); i++ {
+		if flag := redacted[i-1]; flag == "-e" || flag == "--env" {
+			redacted[i], _, _ = strings.Cut(redacted[i], "=")
+		}
+	}
+	return redacted
+}
+
+// exitStatus returns the exit code carried by a command error: 0 for success and -1
+// when the command did not run to completion, e.g. it was not found or was killed
+func exitStatus(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr interface{ ExitCode() int }
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
+
+// commands returns the runner used for docker commands, with the environment from
+// InheritEnv and ExtraEnv applied, logging them when Logger is set
+func (p *DockerComposeProvider) commands() commandRunner {
+	runner := p.runner
+	if r, ok := runner.(execRunner); ok {
+		r.env = p.commandEnv()
+		runner = r
+	}
+
+	if p.Logger == nil {
+		return runner
+	}
+	return loggingRunner{runner: runner, logger: p.Logger}
+}
@@ -0,0 +1,75 @@
+package thirdpartyhosting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Compile-time check that the standard structured logger can be used directly
+var _ Logger = slog.Default()
+
+// capturingLogger records debug entries as "msg key=value ..." lines
+type capturingLogger struct {
+	mu      sync.Mutex
+	entries []string
+}
+
+func (l *capturingLogger) Debug(msg string, args ...interface{}) {
+	entry := msg
+	for i := 0; i+1 < len(args); i += 2 {
+		entry += fmt.Sprintf(" %v=%v", args[i], args[i+1])
+	}
+
+	l.mu.Lock()
+	l.entries = append(l.entries, entry)
+	l.mu.Unlock()
+}
+
+func TestLoggerRecordsCommands(t *testing.T) {
+	provider, runner := newTestProvider(t, testConfig(), func(ctx context.Context, command string) ([]byte, error) {
+		if strings.HasSuffix(command, "ps -q db") {
+			return nil, exitError(1)
+		}
+		return nil, nil
+	})
+	logger := &capturingLogger{}
+	provider.Logger = logger
+
+	assert.NoError(t, provider.Start(context.Background()))
+
+	assert.Len(t, logger.entries, len(runner.Calls()))
+	for _, entry := range logger.entries {
+		assert.Contains(t, entry, "docker command finished command=docker-compose -p test-project")
+		assert.Contains(t, entry, "duration=")
+	}
+	assert.Contains(t, strings.Join(logger.entries, "\n"), "ps -q db duration=")
+	assert.Contains(t, strings.Join(logger.entries, "\n"), "exit_code=1 error=exit status 1")
+}
+
+func TestLoggerRedactsEnvValues(t *testing.T) {
+	assert.Equal(t,
+		[]string{"run", "--rm", "-e", "API_KEY", "--env", "TOKEN", "-e", "PLAIN", "app:latest", "KEY=kept"},
+		redactEnvValues([]string{"run", "--rm", "-e", "API_KEY=s3cret", "--env", "TOKEN=abc=def", "-e", "PLAIN", "app:latest", "KEY=kept"}))
+
+	logger := &capturingLogger{}
+	runner := loggingRunner{runner: &fakeRunner{}, logger: logger}
+	_, err := runner.Run(context.Background(), "docker", "run", "--rm", "-e", "API_KEY=s3cret", "app:latest")
+
+	assert.NoError(t, err)
+	assert.Len(t, logger.entries, 1)
+	assert.Contains(t, logger.entries[0], "command=docker run --rm -e API_KEY app:latest")
+	assert.NotContains(t, logger.entries[0], "s3cret")
+}
+
+func TestExitStatus(t *testing.T) {
+	assert.Equal(t, 0, exitStatus(nil))
+	assert.Equal(t, 3, exitStatus(fmt.Errorf("wrapped: %w", exitError(3))))
+	assert.Equal(t, -1, exitStatus(errors.New("signal: killed")))
+}
@@ -0,0 +1,180 @@
+package thirdpartyhosting
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LogOptions narrows the logs returned by GetLogsFor
+type LogOptions struct {
+	Tail       int    // lines per container from the end of the logs; 0 returns everything
+	Since      string // only logs newer than this, e.g. "10m" or an RFC 3339 timestamp
+	Timestamps bool   // prefix each line with its timestamp
+}
+
+// GetLogsFor returns the logs of the named services, in the order given, with every line
+// prefixed by its service name as docker-compose logs does, e.g. "db  | ready". A service
+// scaled to several containers has each one's logs in turn, prefixed "worker-1", "worker-2"
+// and so on. Output is grouped by container rather than interleaved in time; set
+// Timestamps to order lines across containers.
+func (p *DockerComposeProvider) GetLogsFor(ctx context.Context, services []string, opts LogOptions) (io.Reader, error) {
+	p.mu.RLock()
+	if !p.initialized {
+		p.mu.RUnlock()
+		return nil, fmt.Errorf("provider not initialized")
+	}
+	config := p.config
+	p.mu.RUnlock()
+
+	for _, service := range services {
+		if _, exists := config.Services[service]; !exists {
+			return nil, fmt.Errorf("service %s not found", service)
+		}
+	}
+
+	if err := p.updateContainerIDs(ctx); err != nil {
+		return nil, err
+	}
+
+	type source struct{ service, name, containerID string }
+	var sources []source
+	width := 0
+	for _, service := range services {
+		containerIDs := p.GetContainerIDs(service)
+		if len(containerIDs) == 0 {
+			return nil, fmt.Errorf("container for service %s not found", service)
+		}
+		for i, containerID := range containerIDs {
+			name := service
+			if len(containerIDs) > 1 {
+				name += "-" + strconv.Itoa(i+1)
+			}
+			if len(name) > width {
+				width = len(name)
+			}
+			sources = append(sources, source{service, name, containerID})
+		}
+	}
+
+	var merged bytes.Buffer
+	for _, source := range sources {
+		output, err := p.commands().Run(ctx, "docker", logsArgs(source.containerID, opts)...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get logs for service %s: %w", source.service, err)
+		}
+
+		prefix := source.name + strings.Repeat(" ", width-len(source.name)) + " | "
+		for _, line := range strings.SplitAfter(string(output), "\n") {
+			if line == "" {
+				continue
+			}
+			merged.WriteString(prefix)
+			merged.WriteString(line)
+			if !strings.HasSuffix(line, "\n") {
+				merged.WriteString("\n")
+			}
+		}
+	}
+
+	return &merged, nil
+}
+
+// GetLogsSinceStart returns a service's logs produced since the last Start, leaving out
+// output from earlier runs of a reused container
+func (p *DockerComposeProvider) GetLogsSinceStart(ctx context.Context, serviceName string) (io.Reader, error) {
+	p.mu.RLock()
+	startedAt := p.startedAt
+	p.mu.RUnlock()
+
+	if startedAt.IsZero() {
+		return nil, fmt.Errorf("provider has not been started")
+	}
+
+	containerID, err := p.ResolveContainerID(ctx, serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := p.commands().Run(ctx, "docker", logsArgs(containerID, LogOptions{Since: startedAt.Format(time.RFC3339Nano)})...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get logs for service %s: %w", serviceName, err)
+	}
+
+	return bytes.NewReader(output), nil
+}
+
+// StreamAllLogs follows the logs of every service named in writers, each into its own
+// writer, until ctx is cancelled. A service without a container is polled for until one
+// appears, and a follow that ends because the container stopped or was removed is picked
+// up again from where it left off, so services may come and go while streaming.
+func (p *DockerComposeProvider) StreamAllLogs(ctx context.Context, writers map[string]io.Writer) error {
+	p.mu.RLock()
+	if !p.initialized {
+		p.mu.RUnlock()
+		return fmt.Errorf("provider not initialized")
+	}
+	config := p.config
+	interval := p.pollInterval
+	p.mu.RUnlock()
+
+	for service := range writers {
+		if _, exists := config.Services[service]; !exists {
+			return fmt.Errorf("service %s not found", service)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for service, output := range writers {
+		wg.Add(1)
+		go func(service string, output io.Writer) {
+			defer wg.Done()
+			p.followLogs(ctx, service, output, interval)
+		}(service, output)
+	}
+	wg.Wait()
+
+	return nil
+}
+
+// followLogs streams a service's logs into output until ctx is cancelled, re-following
+// with --since after each follow ends so lines already written are not repeated
+func (p *DockerComposeProvider) followLogs(ctx context.Context, service string, output io.Writer, interval time.Duration) {
+	var since string
+	for {
+		if containerID, err := p.ResolveContainerID(ctx, service); err == nil {
+			args := []string{"logs", "--follow"}
+			if since != "" {
+				args = append(args, "--since", since)
+			}
+			p.commands().Stream(ctx, nil, output, "docker", append(args, containerID)...)
+			since = time.Now().Format(time.RFC3339Nano)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// logsArgs builds the docker logs arguments for a container
+func logsArgs(containerID string, opts LogOptions) []string {
+	args := []string{"logs"}
+	if opts.Tail > 0 {
+		args = append(args, "--tail", strconv.Itoa(opts.Tail))
+	}
+	if opts.Since != "" {
+		args = append(args, "--since", opts.Since)
+	}
+	if opts.Timestamps {
+		args = append(args, "--timestamps")
+	}
+	return append(args, containerID)
+}
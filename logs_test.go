@@ -0,0 +1,214 @@
+package thirdpartyhosting
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetLogsFor(t *testing.T) {
+	config := testConfig()
+	config.Services["cache"] = ServiceConfig{ImageName: "redis"}
+
+	provider, runner := newTestProvider(t, config, func(ctx context.Context, command string) ([]byte, error) {
+		for _, service := range []string{"app", "db", "cache"} {
+			if strings.HasSuffix(command, "ps -q "+service) {
+				return []byte("id-" + service + "\n"), nil
+			}
+		}
+		switch command {
+		case "docker logs --tail 2 id-app":
+			return []byte("listening on :3000\nGET /health 200\n"), nil
+		case "docker logs --tail 2 id-db":
+			return []byte("database system is ready"), nil
+		}
+		return nil, nil
+	})
+
+	reader, err := provider.GetLogsFor(context.Background(), []string{"db", "app"}, LogOptions{Tail: 2})
+	assert.NoError(t, err)
+	logs, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "db  | database system is ready\n"+
+		"app | listening on :3000\n"+
+		"app | GET /health 200\n", string(logs))
+	assert.Empty(t, runner.CallsContaining("docker logs --tail 2 id-cache"))
+}
+
+func TestGetLogsForScaledService(t *testing.T) {
+	provider, _ := newTestProvider(t, testConfig(), func(ctx context.Context, command string) ([]byte, error) {
+		switch {
+		case strings.HasSuffix(command, "ps -q app"):
+			return []byte("id-app-1\nid-app-2\n"), nil
+		case strings.HasSuffix(command, "ps -q db"):
+			return []byte("id-db\n"), nil
+		case command == "docker logs id-app-1":
+			return []byte("listening on :3000\n"), nil
+		case command == "docker logs id-app-2":
+			return []byte("listening on :3001\n"), nil
+		case command == "docker logs id-db":
+			return []byte("database system is ready\n"), nil
+		}
+		return nil, nil
+	})
+
+	reader, err := provider.GetLogsFor(context.Background(), []string{"app", "db"}, LogOptions{})
+	assert.NoError(t, err)
+	logs, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "app-1 | listening on :3000\n"+
+		"app-2 | listening on :3001\n"+
+		"db    | database system is ready\n", string(logs))
+}
+
+func TestGetLogsForUnknownService(t *testing.T) {
+	provider, runner := newTestProvider(t, testConfig(), nil)
+
+	_, err := provider.GetLogsFor(context.Background(), []string{"app", "worker"}, LogOptions{})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "worker")
+	assert.Empty(t, runner.Calls())
+}
+
+func TestLogsArgs(t *testing.T) {
+	assert.Equal(t, []string{"logs", "abc"}, logsArgs("abc", LogOptions{}))
+	assert.Equal(t, []string{"logs", "--tail", "50", "--since", "10m", "--timestamps", "abc"},
+		logsArgs("abc", LogOptions{Tail: 50, Since: "10m", Timestamps: true}))
+}
+
+func TestGetLogsSinceStart(t *testing.T) {
+	provider, runner := newTestProvider(t, testConfig(), func(ctx context.Context, command string) ([]byte, error) {
+		if strings.HasSuffix(command, "ps -q app") {
+			return []byte("id-app\n"), nil
+		}
+		if strings.HasPrefix(command, "docker logs") {
+			return []byte("listening on :3000\n"), nil
+		}
+		return nil, nil
+	})
+
+	require.NoError(t, provider.Start(context.Background()))
+	provider.mu.RLock()
+	firstStart := provider.startedAt
+	provider.mu.RUnlock()
+	require.False(t, firstStart.IsZero())
+
+	reader, err := provider.GetLogsSinceStart(context.Background(), "app")
+	require.NoError(t, err)
+	logs, _ := io.ReadAll(reader)
+	assert.Equal(t, "listening on :3000\n", string(logs))
+	assert.Equal(t, []string{"docker logs --since " + firstStart.Format(time.RFC3339Nano) + " id-app"}, runner.CallsContaining("docker logs"))
+
+	// Each Start moves the cut-off forward
+	require.NoError(t, provider.Start(context.Background()))
+	provider.mu.RLock()
+	secondStart := provider.startedAt
+	provider.mu.RUnlock()
+	assert.True(t, secondStart.After(firstStart))
+
+	_, err = provider.GetLogsSinceStart(context.Background(), "app")
+	require.NoError(t, err)
+	assert.Contains(t, runner.CallsContaining("docker logs")[1], "--since "+secondStart.Format(time.RFC3339Nano))
+}
+
+func TestGetLogsSinceStartBeforeStart(t *testing.T) {
+	provider, runner := newTestProvider(t, testConfig(), nil)
+
+	_, err := provider.GetLogsSinceStart(context.Background(), "app")
+
+	assert.Error(t, err)
+	assert.Empty(t, runner.Calls())
+}
+
+// streamLogsHandler answers ps for app and db, with db's container only appearing after
+// dbAppearsAfter ps calls, and docker logs --follow with a line per service. Follows that
+// resume with --since block until cancelled, like a container with nothing new to log.
+func streamLogsHandler(dbAppearsAfter int) func(ctx context.Context, command string) ([]byte, error) {
+	var mu sync.Mutex
+	dbLookups := 0
+	return func(ctx context.Context, command string) ([]byte, error) {
+		switch {
+		case strings.HasSuffix(command, "ps -q app"):
+			return []byte("app-id\n"), nil
+		case strings.HasSuffix(command, "ps -q db"):
+			mu.Lock()
+			defer mu.Unlock()
+			if dbLookups++; dbLookups <= dbAppearsAfter {
+				return nil, nil
+			}
+			return []byte("db-id\n"), nil
+		case strings.HasPrefix(command, "docker logs --follow --since"):
+			<-ctx.Done()
+			return nil, ctx.Err()
+		case command == "docker logs --follow app-id":
+			return []byte("app listening on :3000\n"), nil
+		case command == "docker logs --follow db-id":
+			return []byte("db ready to accept connections\n"), nil
+		}
+		return nil, nil
+	}
+}
+
+// streamAllLogs runs StreamAllLogs until both services have been followed and resumed
+func streamAllLogs(t *testing.T, provider *DockerComposeProvider, runner *fakeRunner) (app, db *bytes.Buffer) {
+	t.Helper()
+
+	app, db = &bytes.Buffer{}, &bytes.Buffer{}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- provider.StreamAllLogs(ctx, map[string]io.Writer{"app": app, "db": db})
+	}()
+
+	require.Eventually(t, func() bool {
+		return len(runner.CallsContaining("--since")) == 2
+	}, 2*time.Second, time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("StreamAllLogs did not return after cancellation")
+	}
+	return app, db
+}
+
+func TestStreamAllLogs(t *testing.T) {
+	provider, runner := newTestProvider(t, testConfig(), streamLogsHandler(0))
+	provider.pollInterval = time.Millisecond
+
+	app, db := streamAllLogs(t, provider, runner)
+
+	assert.Equal(t, "app listening on :3000\n", app.String())
+	assert.Equal(t, "db ready to accept connections\n", db.String())
+}
+
+func TestStreamAllLogsWaitsForContainer(t *testing.T) {
+	provider, runner := newTestProvider(t, testConfig(), streamLogsHandler(3))
+	provider.pollInterval = time.Millisecond
+
+	_, db := streamAllLogs(t, provider, runner)
+
+	assert.Equal(t, "db ready to accept connections\n", db.String())
+	assert.Len(t, runner.CallsContaining("docker logs --follow db-id"), 1)
+}
+
+func TestStreamAllLogsUnknownService(t *testing.T) {
+	provider, runner := newTestProvider(t, testConfig(), nil)
+
+	err := provider.StreamAllLogs(context.Background(), map[string]io.Writer{"nope": io.Discard})
+
+	assert.Error(t, err)
+	assert.Empty(t, runner.Calls())
+}
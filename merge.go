@@ -0,0 +1,128 @@
+package thirdpartyhosting
+
+import (
+	"reflect"
+	"sort"
+)
+
+// MergeConfigs layers override on top of base the way docker-compose combines a compose
+// file with its override files:
+//   - services only in override are added, and services in both are merged field by field
+//   - set scalars, pointers and slices in override replace base's; zero values such as
+//     an empty string or false leave base's value in place
+//   - maps such as Environment are merged key by key, with override's values winning
+//   - ExposedPorts and ExternalLinks are concatenated, and Volumes are merged by ContainerPath
+//   - Command and CommandShell, like Entrypoint and EntrypointShell, are one setting: setting
+//     either form in override replaces both forms in base
+//
+// BaseDir is kept from base, since compose resolves paths against the first file's directory.
+// Neither input is modified.
+func MergeConfigs(base, override ComposeConfig) ComposeConfig {
+	merged := base.Clone()
+	override = override.Clone()
+
+	mergeFields(reflect.ValueOf(&merged).Elem(), reflect.ValueOf(override), map[string]bool{"Services": true, "BaseDir": true})
+	if merged.BaseDir == "" {
+		merged.BaseDir = override.BaseDir
+	}
+
+	if len(override.Services) > 0 && merged.Services == nil {
+		merged.Services = make(map[string]ServiceConfig, len(override.Services))
+	}
+	for name, service := range override.Services {
+		if existing, exists := merged.Services[name]; exists {
+			service = mergeService(existing, service)
+		}
+		merged.Services[name] = service
+	}
+
+	return merged
+}
+
+// mergeService layers an override service on top of a base service
+func mergeService(base, override ServiceConfig) ServiceConfig {
+	merged := base
+	mergeFields(reflect.ValueOf(&merged).Elem(), reflect.ValueOf(override), map[string]bool{
+		"ExposedPorts":   true,
+		"ExternalLinks":  true,
+		"Volumes":        true,
+		"EnvPassthrough": true,
+	})
+
+	// The list and shell forms are alternatives, so whichever the override sets wins outright
+	if override.Command != nil {
+		merged.CommandShell = ""
+	} else if override.CommandShell != "" {
+		merged.Command = nil
+	}
+	if override.Entrypoint != nil {
+		merged.EntrypointShell = ""
+	} else if override.EntrypointShell != "" {
+		merged.Entrypoint = nil
+	}
+
+	merged.ExposedPorts = append(merged.ExposedPorts, override.ExposedPorts...)
+	merged.ExternalLinks = append(merged.ExternalLinks, override.ExternalLinks...)
+
+	for _, volume := range override.Volumes {
+		replaced := false
+		for i := range merged.Volumes {
+			if merged.Volumes[i].ContainerPath == volume.ContainerPath {
+				merged.Volumes[i] = volume
+				replaced = true
+			}
+		}
+		if !replaced {
+			merged.Volumes = append(merged.Volumes, volume)
+		}
+	}
+
+	// A variable is either passed through or set, whichever the override says last
+	passthrough := make(map[string]bool)
+	for _, key := range base.EnvPassthrough {
+		if _, set := override.Environment[key]; !set {
+			passthrough[key] = true
+		}
+	}
+	for _, key := range override.EnvPassthrough {
+		passthrough[key] = true
+		delete(merged.Environment, key)
+	}
+	merged.EnvPassthrough = nil
+	for key := range passthrough {
+		merged.EnvPassthrough = append(merged.EnvPassthrough, key)
+	}
+	sort.Strings(merged.EnvPassthrough)
+
+	return merged
+}
+
+// mergeFields copies the non-zero fields of src into dst, merging maps key by key and
+// nested structs field by field. Fields named in skip are left for the caller.
+func mergeFields(dst, src reflect.Value, skip map[string]bool) {
+	for i := 0; i < src.NumField(); i++ {
+		if skip[src.Type().Field(i).Name] {
+			continue
+		}
+
+		from, to := src.Field(i), dst.Field(i)
+		if from.IsZero() {
+			continue
+		}
+
+		switch from.Kind() {
+		case reflect.Map:
+			if to.IsNil() {
+				to.Set(reflect.MakeMapWithSize(from.Type(), from.Len()))
+			}
+			iter := from.MapRange()
+			for iter.Next() {
+				to.SetMapIndex(iter.Key(), iter.Value())
+			}
+		case reflect.Struct:
+			mergeFields(to, from, nil)
+		default:
+			to.Set(from)
+		}
+	}
+}
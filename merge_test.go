@@ -0,0 +1,94 @@
+package thirdpartyhosting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeConfigs(t *testing.T) {
+	base := ComposeConfig{
+		ProjectName: "fider",
+		BaseDir:     "/srv/fider",
+		Services: map[string]ServiceConfig{
+			"app": {
+				ImageName:      "getfider/fider",
+				ImageTag:       "stable",
+				Environment:    map[string]string{"LOG_LEVEL": "info", "BASE_URL": "http://localhost"},
+				EnvPassthrough: []string{"SECRET_KEY"},
+				ExposedPorts:   []PortMapping{{HostPort: 3000, ContainerPort: 3000}},
+				Volumes:        []VolumeMapping{{HostPath: "./uploads", ContainerPath: "/app/uploads"}},
+				RestartPolicy:  "always",
+			},
+			"db": {ImageName: "postgres", ImageTag: "13"},
+		},
+	}
+	override := ComposeConfig{
+		BaseDir: "/srv/fider/overrides",
+		Services: map[string]ServiceConfig{
+			"app": {
+				ImageTag:       "main",
+				Environment:    map[string]string{"LOG_LEVEL": "debug", "SECRET_KEY": "dev"},
+				EnvPassthrough: []string{"BASE_URL"},
+				ExposedPorts:   []PortMapping{{HostPort: 9229, ContainerPort: 9229}},
+				Volumes:        []VolumeMapping{{HostPath: "./dev-uploads", ContainerPath: "/app/uploads"}, {HostPath: "./src", ContainerPath: "/app/src"}},
+			},
+			"mailhog": {ImageName: "mailhog/mailhog"},
+		},
+	}
+
+	merged := MergeConfigs(base, override)
+
+	assert.Equal(t, ComposeConfig{
+		ProjectName: "fider",
+		BaseDir:     "/srv/fider",
+		Services: map[string]ServiceConfig{
+			"app": {
+				ImageName:      "getfider/fider",
+				ImageTag:       "main",
+				Environment:    map[string]string{"LOG_LEVEL": "debug", "SECRET_KEY": "dev"},
+				EnvPassthrough: []string{"BASE_URL"},
+				ExposedPorts:   []PortMapping{{HostPort: 3000, ContainerPort: 3000}, {HostPort: 9229, ContainerPort: 9229}},
+				Volumes:        []VolumeMapping{{HostPath: "./dev-uploads", ContainerPath: "/app/uploads"}, {HostPath: "./src", ContainerPath: "/app/src"}},
+				RestartPolicy:  "always",
+			},
+			"db":      {ImageName: "postgres", ImageTag: "13"},
+			"mailhog": {ImageName: "mailhog/mailhog"},
+		},
+	}, merged)
+
+	// The inputs are left alone
+	assert.Equal(t, "info", base.Services["app"].Environment["LOG_LEVEL"])
+	assert.Len(t, base.Services["app"].ExposedPorts, 1)
+}
+
+func TestMergeConfigsNestedStructs(t *testing.T) {
+	swappiness := 10
+	base := ComposeConfig{Services: map[string]ServiceConfig{
+		"db": {ImageName: "postgres", Resources: ResourceLimits{Memory: "512m", CPUShare: "0.5"}},
+	}}
+	override := ComposeConfig{Services: map[string]ServiceConfig{
+		"db": {Resources: ResourceLimits{Memory: "1g", MemSwappiness: &swappiness}},
+	}}
+
+	merged := MergeConfigs(base, override)
+
+	assert.Equal(t, ResourceLimits{Memory: "1g", CPUShare: "0.5", MemSwappiness: &swappiness}, merged.Services["db"].Resources)
+}
+
+func TestMergeConfigsCommandForms(t *testing.T) {
+	base := ComposeConfig{Services: map[string]ServiceConfig{
+		"app": {ImageName: "app", Command: []string{"npm", "start"}, EntrypointShell: "/entrypoint.sh"},
+	}}
+	override := ComposeConfig{Services: map[string]ServiceConfig{
+		"app": {CommandShell: "npm run dev", Entrypoint: []string{}},
+	}}
+
+	app := MergeConfigs(base, override).Services["app"]
+
+	assert.Nil(t, app.Command)
+	assert.Equal(t, "npm run dev", app.CommandShell)
+	assert.Equal(t, []string{}, app.Entrypoint)
+	assert.Empty(t, app.EntrypointShell)
+	assert.NoError(t, ValidateConfig(ComposeConfig{Services: map[string]ServiceConfig{"app": app}}))
+}
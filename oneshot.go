@@ -0,0 +1,65 @@
+package thirdpartyhosting
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+)
+
+// RunOneShot runs svc as a single throwaway container with docker run --rm, joined to the
+// project's default network so it can reach the stack's services by name. It suits
+// migrations and seeders that don't belong in the long-running stack. A non-zero exit is
+// reported through exitCode rather than err; err is only set when the container could not
+// be run, in which case the container is removed before returning.
+func (p *DockerComposeProvider) RunOneShot(ctx context.Context, svc ServiceConfig, args []string) (exitCode int, output string, err error) {
+	p.mu.RLock()
+	if !p.initialized {
+		p.mu.RUnlock()
+		return -1, "", fmt.Errorf("provider not initialized")
+	}
+	config := p.config
+	p.mu.RUnlock()
+
+	if svc.ImageName == "" {
+		return -1, "", fmt.Errorf("one-shot container requires an image")
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return -1, "", fmt.Errorf("failed to generate container name: %w", err)
+	}
+	project := normalizeProjectName(config.ProjectName)
+	name := project + "-oneshot-" + hex.EncodeToString(suffix)
+
+	runArgs := []string{"run", "--rm", "--name", name, "--network", project + "_default"}
+	for _, key := range sortedKeys(svc.Environment) {
+		runArgs = append(runArgs, "-e", key+"="+svc.Environment[key])
+	}
+	for _, volume := range svc.Volumes {
+		if volumeMappingType(volume) == "bind" {
+			volume.HostPath = resolveRelativePath(config.BaseDir, volume.HostPath)
+		}
+		spec := volume.HostPath + ":" + volume.ContainerPath
+		if volume.ReadOnly {
+			spec += ":ro"
+		}
+		runArgs = append(runArgs, "-v", spec)
+	}
+	runArgs = append(runArgs, imageRef(svc))
+	runArgs = append(runArgs, args...)
+
+	result, err := p.commands().Run(ctx, "docker", runArgs...)
+	if err == nil {
+		return 0, string(result), nil
+	}
+
+	// docker run exits with the container's status, except 125-127 which mean docker itself failed
+	if code := exitStatus(err); code > 0 && code < 125 && ctx.Err() == nil {
+		return code, string(result), nil
+	}
+
+	// --rm doesn't cover a docker run that was killed or failed, so remove the container explicitly
+	p.commands().Run(context.Background(), "docker", "rm", "-f", name)
+	return -1, string(result), fmt.Errorf("failed to run one-shot container: %s, error: %w", string(result), err)
+}
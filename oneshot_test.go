@@ -0,0 +1,81 @@
+package thirdpartyhosting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// exitError mimics *exec.ExitError for a process that exited with code
+type exitError int
+
+func (e exitError) Error() string { return fmt.Sprintf("exit status %d", int(e)) }
+func (e exitError) ExitCode() int { return int(e) }
+
+func TestRunOneShot(t *testing.T) {
+	provider, runner := newTestProvider(t, testConfig(), func(ctx context.Context, command string) ([]byte, error) {
+		if strings.HasPrefix(command, "docker run") {
+			return []byte("applied 3 migrations\n"), nil
+		}
+		return nil, nil
+	})
+
+	exitCode, output, err := provider.RunOneShot(context.Background(), ServiceConfig{
+		ImageName:   "migrate/migrate",
+		ImageTag:    "v4",
+		Environment: map[string]string{"DB_HOST": "db"},
+	}, []string{"up"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, exitCode)
+	assert.Equal(t, "applied 3 migrations\n", output)
+
+	calls := runner.CallsContaining("docker run")
+	assert.Len(t, calls, 1)
+	assert.Regexp(t, `^docker run --rm --name test-project-oneshot-[0-9a-f]{8} --network test-project_default -e DB_HOST=db migrate/migrate:v4 up$`, calls[0])
+	assert.Empty(t, runner.CallsContaining("docker rm"))
+}
+
+func TestRunOneShotNonZeroExit(t *testing.T) {
+	provider, runner := newTestProvider(t, testConfig(), func(ctx context.Context, command string) ([]byte, error) {
+		if strings.HasPrefix(command, "docker run") {
+			return []byte("seed failed\n"), exitError(3)
+		}
+		return nil, nil
+	})
+
+	exitCode, output, err := provider.RunOneShot(context.Background(), ServiceConfig{ImageName: "seeder"}, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, exitCode)
+	assert.Equal(t, "seed failed\n", output)
+	assert.Empty(t, runner.CallsContaining("docker rm"))
+}
+
+func TestRunOneShotCleansUpOnFailure(t *testing.T) {
+	provider, runner := newTestProvider(t, testConfig(), func(ctx context.Context, command string) ([]byte, error) {
+		if strings.HasPrefix(command, "docker run") {
+			return []byte("docker: Error response from daemon\n"), errors.New("signal: killed")
+		}
+		return nil, nil
+	})
+
+	exitCode, _, err := provider.RunOneShot(context.Background(), ServiceConfig{ImageName: "seeder"}, nil)
+
+	assert.Error(t, err)
+	assert.Equal(t, -1, exitCode)
+	assert.Len(t, runner.CallsContaining("docker rm -f test-project-oneshot-"), 1)
+}
+
+func TestRunOneShotRequiresImage(t *testing.T) {
+	provider, runner := newTestProvider(t, testConfig(), nil)
+
+	_, _, err := provider.RunOneShot(context.Background(), ServiceConfig{}, nil)
+
+	assert.Error(t, err)
+	assert.Empty(t, runner.Calls())
+}
@@ -0,0 +1,119 @@
+package thirdpartyhosting
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PublishedPort describes a container port and the host address Docker published it on
+type PublishedPort struct {
+	ContainerPort int
+	Protocol      string // "tcp", "udp" or "sctp"
+	HostIP        string // e.g., "0.0.0.0" or "::"
+	HostPort      int
+}
+
+// GetPublishedPorts returns the host ports actually assigned to a service's container.
+// This is how callers discover the port Docker picked when HostPort is 0.
+func (p *DockerComposeProvider) GetPublishedPorts(ctx context.Context, serviceName string) ([]PublishedPort, error) {
+	containerID, err := p.ResolveContainerID(ctx, serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := p.commands().Run(ctx, "docker", "port", containerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get published ports: %s, error: %w", string(output), err)
+	}
+
+	return parsePortOutput(string(output))
+}
+
+// parsePortOutput parses `docker port` lines such as "80/tcp -> 0.0.0.0:49153"
+func parsePortOutput(output string) ([]PublishedPort, error) {
+	var ports []PublishedPort
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		parts := strings.SplitN(line, " -> ", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("unexpected docker port output %q", line)
+		}
+
+		containerPort, protocol := parts[0], defaultProtocol
+		if i := strings.Index(containerPort, "/"); i >= 0 {
+			containerPort, protocol = containerPort[:i], containerPort[i+1:]
+		}
+		port, err := strconv.Atoi(containerPort)
+		if err != nil {
+			return nil, fmt.Errorf("invalid container port in %q: %w", line, err)
+		}
+
+		hostIP, hostPort, err := net.SplitHostPort(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid host address in %q: %w", line, err)
+		}
+		published, err := strconv.Atoi(hostPort)
+		if err != nil {
+			return nil, fmt.Errorf("invalid host port in %q: %w", line, err)
+		}
+
+		ports = append(ports, PublishedPort{
+			ContainerPort: port,
+			Protocol:      protocol,
+			HostIP:        hostIP,
+			HostPort:      published,
+		})
+	}
+
+	return ports, nil
+}
+
+// checkPortsAvailable binds each fixed host port published by the active services to
+// report one that is already taken, before docker-compose fails on it less clearly.
+// Ephemeral (0) and sctp ports are skipped.
+func checkPortsAvailable(config ComposeConfig) error {
+	names := make([]string, 0, len(config.Services))
+	for name := range config.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		service := config.Services[name]
+		if !isServiceActive(config, service) {
+			continue
+		}
+
+		for _, port := range service.ExposedPorts {
+			if port.HostPort == 0 {
+				continue
+			}
+
+			address := ":" + strconv.Itoa(port.HostPort)
+			switch protocol := strings.ToLower(port.Protocol); protocol {
+			case "", "tcp":
+				listener, err := net.Listen("tcp", address)
+				if err != nil {
+					return fmt.Errorf("service %s: port %d already in use: %w", name, port.HostPort, err)
+				}
+				listener.Close()
+			case "udp":
+				conn, err := net.ListenPacket("udp", address)
+				if err != nil {
+					return fmt.Errorf("service %s: port %d/udp already in use: %w", name, port.HostPort, err)
+				}
+				conn.Close()
+			}
+		}
+	}
+
+	return nil
+}
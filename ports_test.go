@@ -0,0 +1,102 @@
+package thirdpartyhosting
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPublishedPortsRandomAssignment(t *testing.T) {
+	provider, runner := newTestProvider(t, testConfig(), func(ctx context.Context, command string) ([]byte, error) {
+		switch {
+		case strings.HasSuffix(command, "ps -q app"):
+			return []byte("id-app\n"), nil
+		case command == "docker port id-app":
+			return []byte("80/tcp -> 0.0.0.0:49153\n80/tcp -> [::]:49153\n5353/udp -> 127.0.0.1:5353\n"), nil
+		}
+		return nil, nil
+	})
+
+	ports, err := provider.GetPublishedPorts(context.Background(), "app")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []PublishedPort{
+		{ContainerPort: 80, Protocol: "tcp", HostIP: "0.0.0.0", HostPort: 49153},
+		{ContainerPort: 80, Protocol: "tcp", HostIP: "::", HostPort: 49153},
+		{ContainerPort: 5353, Protocol: "udp", HostIP: "127.0.0.1", HostPort: 5353},
+	}, ports)
+	assert.Len(t, runner.CallsContaining("docker port"), 1)
+}
+
+func TestGetPublishedPortsUnknownService(t *testing.T) {
+	provider, _ := newTestProvider(t, testConfig(), nil)
+
+	_, err := provider.GetPublishedPorts(context.Background(), "missing")
+
+	assert.Error(t, err)
+}
+
+func TestParsePortOutputInvalid(t *testing.T) {
+	_, err := parsePortOutput("garbage\n")
+
+	assert.Error(t, err)
+}
+
+func TestStartFailsWhenPortInUse(t *testing.T) {
+	listener, err := net.Listen("tcp", ":0")
+	require.NoError(t, err)
+	defer listener.Close()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	config := testConfig()
+	app := config.Services["app"]
+	app.ExposedPorts = []PortMapping{{HostPort: 0, ContainerPort: 9000}, {HostPort: port, ContainerPort: 80}}
+	config.Services["app"] = app
+
+	provider, runner := newTestProvider(t, config, nil)
+	provider.CheckPorts = true
+
+	err = provider.Start(context.Background())
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), fmt.Sprintf("service app: port %d already in use", port))
+	assert.Empty(t, runner.Calls())
+}
+
+func TestStartWithFreePorts(t *testing.T) {
+	listener, err := net.Listen("tcp", ":0")
+	require.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	config := testConfig()
+	app := config.Services["app"]
+	app.ExposedPorts = []PortMapping{{HostPort: port, ContainerPort: 80}}
+	config.Services["app"] = app
+
+	provider, runner := newTestProvider(t, config, nil)
+	provider.CheckPorts = true
+
+	require.NoError(t, provider.Start(context.Background()))
+	assert.Len(t, runner.CallsContaining("up -d"), 1)
+}
+
+func TestCheckPortsAvailableSkipsInactiveServices(t *testing.T) {
+	listener, err := net.Listen("tcp", ":0")
+	require.NoError(t, err)
+	defer listener.Close()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	config := ComposeConfig{
+		Services: map[string]ServiceConfig{
+			"debug": {ImageName: "busybox", Profiles: []string{"debug"}, ExposedPorts: []PortMapping{{HostPort: port, ContainerPort: 80}}},
+		},
+	}
+
+	assert.NoError(t, checkPortsAvailable(config))
+}
@@ -0,0 +1,19 @@
+package thirdpartyhosting
+
+// isServiceActive reports whether a service is enabled by the config's active profiles.
+// Services without profiles are always active.
+func isServiceActive(config ComposeConfig, service ServiceConfig) bool {
+	if len(service.Profiles) == 0 {
+		return true
+	}
+
+	for _, profile := range service.Profiles {
+		for _, active := range config.ActiveProfiles {
+			if profile == active {
+				return true
+			}
+		}
+	}
+
+	return false
+}
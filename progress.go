@@ -0,0 +1,143 @@
+package thirdpartyhosting
+
+import (
+	"bytes"
+	"context"
+	"strings"
+)
+
+// ProgressEvent reports a step docker-compose took for a single service during Start
+type ProgressEvent struct {
+	Service string // service name from the compose configuration
+	Action  string // e.g., "Creating", "Created", "Starting", "Started", "Error"
+	Line    string // the raw output line the event was parsed from
+}
+
+// legacyProgressActions maps docker-compose v1 "<verb> <container> ... <result>" lines to completed actions
+var legacyProgressActions = map[string]string{
+	"Creating":   "Created",
+	"Recreating": "Recreated",
+	"Starting":   "Started",
+	"Pulling":    "Pulled",
+}
+
+// parseProgressLine extracts a ProgressEvent from a line of docker-compose up output.
+// Both the v2 format (" Container proj-web-1  Started") and the v1 format
+// ("Creating proj_web_1 ... done") are understood. Lines that don't refer to
+// one of services are ignored.
+func parseProgressLine(line, project string, services map[string]ServiceConfig) (ProgressEvent, bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 2 {
+		return ProgressEvent{}, false
+	}
+
+	var container, action string
+	switch {
+	case fields[0] == "Container" && len(fields) >= 3:
+		container = fields[1]
+		action = fields[2]
+		if strings.HasPrefix(action, "Error") {
+			action = "Error"
+		}
+	case len(fields) >= 4 && fields[2] == "...":
+		container = fields[1]
+		switch result := fields[3]; {
+		case result == "done":
+			action = legacyProgressActions[fields[0]]
+			if action == "" {
+				action = fields[0]
+			}
+		case strings.HasPrefix(result, "error"):
+			action = "Error"
+		default:
+			action = fields[0]
+		}
+	case len(fields) == 3 && fields[2] == "...":
+		container = fields[1]
+		action = fields[0]
+	default:
+		return ProgressEvent{}, false
+	}
+
+	service := serviceFromContainerName(container, project)
+	if _, exists := services[service]; !exists {
+		return ProgressEvent{}, false
+	}
+
+	return ProgressEvent{Service: service, Action: action, Line: line}, true
+}
+
+// serviceFromContainerName strips the project prefix and replica index compose adds to container names
+func serviceFromContainerName(container, project string) string {
+	name := container
+	for _, sep := range []string{"-", "_"} {
+		if strings.HasPrefix(name, project+sep) {
+			name = strings.TrimPrefix(name, project+sep)
+			break
+		}
+	}
+
+	if i := strings.LastIndexAny(name, "-_"); i > 0 && isDigits(name[i+1:]) {
+		name = name[:i]
+	}
+	return name
+}
+
+// isDigits reports whether s is a non-empty string of ASCII digits
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// progressWriter emits a ProgressEvent for every recognised line of command output
+type progressWriter struct {
+	ctx      context.Context
+	project  string
+	services map[string]ServiceConfig
+	events   chan<- ProgressEvent
+	partial  []byte
+}
+
+// Write parses any complete lines p finishes
+func (w *progressWriter) Write(p []byte) (int, error) {
+	w.partial = append(w.partial, p...)
+
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i < 0 {
+			break
+		}
+		w.emit(string(w.partial[:i]))
+		w.partial = w.partial[i+1:]
+	}
+
+	return len(p), nil
+}
+
+// Flush parses any trailing line that wasn't newline-terminated
+func (w *progressWriter) Flush() {
+	if len(w.partial) > 0 {
+		w.emit(string(w.partial))
+		w.partial = nil
+	}
+}
+
+// emit sends the event for line, if any, unless the context is done
+func (w *progressWriter) emit(line string) {
+	event, ok := parseProgressLine(strings.TrimRight(line, "\r"), w.project, w.services)
+	if !ok {
+		return
+	}
+
+	select {
+	case w.events <- event:
+	case <-w.ctx.Done():
+	}
+}
@@ -0,0 +1,90 @@
+package thirdpartyhosting
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseProgressLine(t *testing.T) {
+	services := testConfig().Services
+	lines := []string{
+		" Network test-project_default  Creating",
+		" Container test-project-db-1  Creating",
+		" Container test-project-db-1  Created",
+		" Container test-project-app-1  Starting",
+		" Container test-project-app-1  Started",
+		" Container test-project-app-1  Error response from daemon: port is already allocated",
+		"Creating test-project_db_1 ... done",
+		"Creating test-project_app_1 ... error",
+		"Starting test-project_app_1 ...",
+		"some unrelated output",
+	}
+
+	var events []ProgressEvent
+	for _, line := range lines {
+		if event, ok := parseProgressLine(line, "test-project", services); ok {
+			events = append(events, event)
+		}
+	}
+
+	expected := []ProgressEvent{
+		{Service: "db", Action: "Creating"},
+		{Service: "db", Action: "Created"},
+		{Service: "app", Action: "Starting"},
+		{Service: "app", Action: "Started"},
+		{Service: "app", Action: "Error"},
+		{Service: "db", Action: "Created"},
+		{Service: "app", Action: "Error"},
+		{Service: "app", Action: "Starting"},
+	}
+	if assert.Len(t, events, len(expected)) {
+		for i, event := range events {
+			assert.Equal(t, expected[i].Service, event.Service, event.Line)
+			assert.Equal(t, expected[i].Action, event.Action, event.Line)
+		}
+	}
+}
+
+func TestStartEmitsProgressEvents(t *testing.T) {
+	provider, _ := newTestProvider(t, testConfig(), func(ctx context.Context, command string) ([]byte, error) {
+		if strings.Contains(command, "up -d") {
+			return []byte(" Container test-project-db-1  Started\n Container test-project-app-1  Started"), nil
+		}
+		return nil, nil
+	})
+
+	events := make(chan ProgressEvent, 10)
+	provider.Progress = events
+
+	assert.NoError(t, provider.Start(context.Background()))
+	close(events)
+
+	var services []string
+	for event := range events {
+		assert.Equal(t, "Started", event.Action)
+		services = append(services, event.Service)
+	}
+	assert.Equal(t, []string{"db", "app"}, services)
+}
+
+func TestStartProgressKeepsOutputOnError(t *testing.T) {
+	provider, _ := newTestProvider(t, testConfig(), func(ctx context.Context, command string) ([]byte, error) {
+		if strings.Contains(command, "up -d") {
+			return []byte(" Container test-project-app-1  Error response from daemon\n"), errors.New("exit status 1")
+		}
+		return nil, nil
+	})
+
+	events := make(chan ProgressEvent, 10)
+	provider.Progress = events
+
+	err := provider.Start(context.Background())
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Error response from daemon")
+	assert.Equal(t, "Error", (<-events).Action)
+}
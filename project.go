@@ -0,0 +1,53 @@
+package thirdpartyhosting
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+)
+
+// normalizeProjectName applies docker-compose's project name rules: lowercase,
+// only letters, digits, dashes and underscores, starting with a letter or digit
+func normalizeProjectName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' || r == '_' {
+			b.WriteRune(r)
+		}
+	}
+
+	return strings.TrimLeft(b.String(), "-_")
+}
+
+// projectLabel is the label docker-compose sets on every container it creates for a project
+const projectLabel = "com.docker.compose.project"
+
+// projectLabelFilter returns the docker --filter value selecting a project's containers.
+// The name is normalized the same way as the -p flag so the filter matches the label
+// compose actually wrote; arguments are passed without a shell, so no quoting is needed.
+func projectLabelFilter(projectName string) string {
+	return "label=" + projectLabel + "=" + normalizeProjectName(projectName)
+}
+
+// WithUniqueProjectName appends a random suffix to base and normalizes the result, so
+// providers built from the same base, e.g. in parallel tests, don't share containers
+func WithUniqueProjectName(base string) string {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		panic("thirdpartyhosting: failed to generate project name suffix: " + err.Error())
+	}
+
+	name := normalizeProjectName(base)
+	if name == "" {
+		return hex.EncodeToString(suffix)
+	}
+	return name + "-" + hex.EncodeToString(suffix)
+}
+
+// GetProjectName returns the normalized project name passed to docker-compose
+func (p *DockerComposeProvider) GetProjectName() string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return normalizeProjectName(p.config.ProjectName)
+}
@@ -0,0 +1,80 @@
+package thirdpartyhosting
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeProjectName(t *testing.T) {
+	cases := map[string]string{
+		"my-app":      "my-app",
+		"My Project":  "myproject",
+		"MyApp":       "myapp",
+		"123app":      "123app",
+		"_Leading":    "leading",
+		"app.v2":      "appv2",
+		"snake_case":  "snake_case",
+		"  spaced  ":  "spaced",
+		"--dashes--1": "dashes--1",
+	}
+
+	for input, expected := range cases {
+		assert.Equal(t, expected, normalizeProjectName(input), "input %q", input)
+	}
+}
+
+func TestGetProjectNameUsedForCommands(t *testing.T) {
+	config := testConfig()
+	config.ProjectName = "My Project"
+
+	provider, runner := newTestProvider(t, config, nil)
+
+	assert.Equal(t, "myproject", provider.GetProjectName())
+	assert.NoError(t, provider.Start(context.Background()))
+	assert.Len(t, runner.CallsContaining("-p myproject "), len(runner.CallsContaining("docker-compose")))
+}
+
+func TestGetProjectNameUninitialized(t *testing.T) {
+	assert.Empty(t, NewDockerComposeProvider().GetProjectName())
+}
+
+func TestWithUniqueProjectName(t *testing.T) {
+	first := testConfig()
+	first.ProjectName = WithUniqueProjectName("Integration Tests")
+	second := testConfig()
+	second.ProjectName = WithUniqueProjectName("Integration Tests")
+
+	firstProvider, runner := newTestProvider(t, first, nil)
+	secondProvider, _ := newTestProvider(t, second, nil)
+
+	assert.NotEqual(t, firstProvider.GetProjectName(), secondProvider.GetProjectName())
+	assert.Regexp(t, `^integrationtests-[0-9a-f]{8}$`, firstProvider.GetProjectName())
+	assert.Equal(t, first.ProjectName, firstProvider.GetProjectName())
+
+	assert.NoError(t, firstProvider.Start(context.Background()))
+	assert.Len(t, runner.CallsContaining("-p "+first.ProjectName+" "), len(runner.CallsContaining("docker-compose")))
+}
+
+func TestProjectLabelFilter(t *testing.T) {
+	cases := map[string]string{
+		"my-app":       "label=com.docker.compose.project=my-app",
+		"snake_case":   "label=com.docker.compose.project=snake_case",
+		"api.v2.stage": "label=com.docker.compose.project=apiv2stage",
+		"Team's App!":  "label=com.docker.compose.project=teamsapp",
+	}
+
+	for name, expected := range cases {
+		assert.Equal(t, expected, projectLabelFilter(name), "project %q", name)
+	}
+}
+
+func TestProjectLabelFilterMatchesComposeFlag(t *testing.T) {
+	config := testConfig()
+	config.ProjectName = "api.v2.stage"
+
+	args := composeArgs(config, "", "ps")
+
+	assert.Equal(t, "label=com.docker.compose.project="+args[1], projectLabelFilter(config.ProjectName))
+}
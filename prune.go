@@ -0,0 +1,63 @@
+package thirdpartyhosting
+
+import (
+	"context"
+	"fmt"
+)
+
+// Prune removes the unused volumes and networks that carry every one of labels, e.g. the
+// VolumeLabels and NetworkLabels of stacks that have been stopped. Docker leaves volumes
+// and networks still in use alone. At least one label is required so Prune never removes
+// unrelated resources.
+func (p *DockerComposeProvider) Prune(ctx context.Context, labels map[string]string) error {
+	if len(labels) == 0 {
+		return fmt.Errorf("prune needs at least one label to filter by")
+	}
+
+	var filters []string
+	for _, key := range sortedKeys(labels) {
+		filter := "label=" + key
+		if value := labels[key]; value != "" {
+			filter += "=" + value
+		}
+		filters = append(filters, "--filter", filter)
+	}
+
+	return p.prune(ctx, filters)
+}
+
+// PruneProject removes the unused volumes and networks docker-compose created for the
+// initialized project, found by the project label compose puts on them, e.g. to drop a
+// stopped stack's named volumes. Resources of other projects are left alone.
+func (p *DockerComposeProvider) PruneProject(ctx context.Context) error {
+	p.mu.RLock()
+	if !p.initialized {
+		p.mu.RUnlock()
+		return fmt.Errorf("provider not initialized")
+	}
+	projectName := p.config.ProjectName
+	p.mu.RUnlock()
+
+	// An empty label value would match every resource without a project
+	if normalizeProjectName(projectName) == "" {
+		return fmt.Errorf("prune needs a project name to filter by")
+	}
+
+	return p.prune(ctx, []string{"--filter", projectLabelFilter(projectName)})
+}
+
+// prune removes the unused volumes and networks matching the docker --filter arguments
+func (p *DockerComposeProvider) prune(ctx context.Context, filters []string) error {
+	// --all includes named volumes, which volume prune otherwise keeps
+	volumeArgs := append([]string{"volume", "prune", "--force", "--all"}, filters...)
+	if output, err := p.commands().Run(ctx, "docker", volumeArgs...); err != nil {
+		return fmt.Errorf("failed to prune volumes: %s, error: %w", string(output), err)
+	}
+
+	networkArgs := append([]string{"network", "prune", "--force"}, filters...)
+	if output, err := p.commands().Run(ctx, "docker", networkArgs...); err != nil {
+		return fmt.Errorf("failed to prune networks: %s, error: %w", string(output), err)
+	}
+
+	return nil
+}
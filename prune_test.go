@@ -0,0 +1,63 @@
+package thirdpartyhosting
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPruneFiltersByLabels(t *testing.T) {
+	provider, runner := newTestProvider(t, testConfig(), nil)
+
+	require.NoError(t, provider.Prune(context.Background(), map[string]string{"owner": "ci", "ephemeral": ""}))
+
+	assert.Equal(t, []string{
+		"docker volume prune --force --all --filter label=ephemeral --filter label=owner=ci",
+		"docker network prune --force --filter label=ephemeral --filter label=owner=ci",
+	}, runner.Calls())
+}
+
+func TestPruneRequiresLabels(t *testing.T) {
+	provider, runner := newTestProvider(t, testConfig(), nil)
+
+	assert.Error(t, provider.Prune(context.Background(), nil))
+	assert.Empty(t, runner.Calls())
+}
+
+func TestPruneVolumeFailure(t *testing.T) {
+	provider, runner := newTestProvider(t, testConfig(), func(ctx context.Context, command string) ([]byte, error) {
+		return []byte("permission denied"), errors.New("exit status 1")
+	})
+
+	err := provider.Prune(context.Background(), map[string]string{"owner": "ci"})
+
+	assert.ErrorContains(t, err, "failed to prune volumes: permission denied")
+	assert.Len(t, runner.Calls(), 1)
+}
+
+func TestPruneProject(t *testing.T) {
+	config := testConfig()
+	config.ProjectName = "api.v2.stage"
+	provider, runner := newTestProvider(t, config, nil)
+
+	require.NoError(t, provider.PruneProject(context.Background()))
+
+	assert.Equal(t, []string{
+		"docker volume prune --force --all --filter label=com.docker.compose.project=apiv2stage",
+		"docker network prune --force --filter label=com.docker.compose.project=apiv2stage",
+	}, runner.Calls())
+}
+
+func TestPruneProjectRequiresProjectName(t *testing.T) {
+	assert.Error(t, NewDockerComposeProvider().PruneProject(context.Background()))
+
+	config := testConfig()
+	config.ProjectName = "!!!"
+	provider, runner := newTestProvider(t, config, nil)
+
+	assert.Error(t, provider.PruneProject(context.Background()))
+	assert.Empty(t, runner.Calls())
+}
@@ -0,0 +1,84 @@
+package thirdpartyhosting
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Pull fetches the images for all services with docker-compose pull, skipping services
+// whose image is built locally or must never be pulled; see PullImages
+func (p *DockerComposeProvider) Pull(ctx context.Context) error {
+	_, _, err := p.PullImages(ctx)
+	return err
+}
+
+// PullImages fetches the images for all active services and reports which were pulled and
+// which were skipped, either because pullSkipped holds for them or because their pull policy
+// is "missing" and docker image inspect finds the image locally. Naming the pulled services
+// keeps docker-compose from trying to pull images that only exist once built.
+func (p *DockerComposeProvider) PullImages(ctx context.Context) (pulled, skipped []string, err error) {
+	defer p.observe("pull", time.Now(), &err)
+
+	p.mu.RLock()
+	if !p.initialized {
+		p.mu.RUnlock()
+		return nil, nil, fmt.Errorf("provider not initialized")
+	}
+	config := p.config
+	p.mu.RUnlock()
+
+	ctx, cancel := p.Timeouts.withTimeout(ctx, p.Timeouts.Pull)
+	defer cancel()
+
+	for name, service := range config.Services {
+		switch {
+		case !isServiceActive(config, service):
+		case pullSkipped(service), service.PullPolicy == "missing" && p.imagePresent(ctx, service):
+			skipped = append(skipped, name)
+		default:
+			pulled = append(pulled, name)
+		}
+	}
+	sort.Strings(pulled)
+	sort.Strings(skipped)
+	if len(pulled) == 0 {
+		return nil, skipped, nil
+	}
+
+	composeFile, _, err := p.renderComposeFile(config, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	args := composeArgs(config, composeFile, append([]string{"pull"}, pulled...)...)
+	output, err := p.runCompose(ctx, nil, args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to pull images: %s, error: %w", string(output), err)
+	}
+
+	return pulled, skipped, nil
+}
+
+// imagePresent reports whether a service's image exists locally. Any inspect failure,
+// including docker itself failing, counts as absent so the pull is still attempted.
+func (p *DockerComposeProvider) imagePresent(ctx context.Context, service ServiceConfig) bool {
+	if service.ImageName == "" {
+		return false
+	}
+	_, err := p.commands().Run(ctx, "docker", "image", "inspect", "--format", "{{.Id}}", imageRef(service))
+	return err == nil
+}
+
+// pullSkipped reports whether a service's image must not be pulled: its pull policy is
+// "never" or "build", or it is built locally and doesn't ask for "always" or "missing"
+func pullSkipped(service ServiceConfig) bool {
+	switch service.PullPolicy {
+	case "never", "build":
+		return true
+	case "always", "missing":
+		return false
+	}
+	return service.Build != nil
+}
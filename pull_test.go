@@ -0,0 +1,150 @@
+package thirdpartyhosting
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPullOnStartPrecedesUp(t *testing.T) {
+	provider, runner := newTestProvider(t, testConfig(), nil)
+	provider.PullOnStart = true
+
+	assert.NoError(t, provider.Start(context.Background()))
+
+	pull, up := -1, -1
+	for i, call := range runner.Calls() {
+		switch {
+		case strings.HasSuffix(call, " pull app db"):
+			pull = i
+		case strings.HasSuffix(call, " up -d"):
+			up = i
+		}
+	}
+	assert.True(t, pull >= 0 && up > pull, "pull must run before up: %v", runner.Calls())
+}
+
+func TestPullFailureAbortsStart(t *testing.T) {
+	provider, runner := newTestProvider(t, testConfig(), func(ctx context.Context, command string) ([]byte, error) {
+		if strings.Contains(command, " pull ") {
+			return []byte("manifest unknown"), errors.New("exit status 1")
+		}
+		return nil, nil
+	})
+	provider.PullOnStart = true
+
+	err := provider.Start(context.Background())
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "manifest unknown")
+	assert.Empty(t, runner.CallsContaining("up -d"))
+}
+
+func TestPullFailureIgnored(t *testing.T) {
+	provider, runner := newTestProvider(t, testConfig(), func(ctx context.Context, command string) ([]byte, error) {
+		if strings.Contains(command, " pull ") {
+			return nil, errors.New("exit status 1")
+		}
+		return nil, nil
+	})
+	provider.PullOnStart = true
+	provider.IgnorePullFailure = true
+
+	assert.NoError(t, provider.Start(context.Background()))
+	assert.Len(t, runner.CallsContaining("up -d"), 1)
+}
+
+func TestNoPullWithoutPullOnStart(t *testing.T) {
+	provider, runner := newTestProvider(t, testConfig(), nil)
+
+	assert.NoError(t, provider.Start(context.Background()))
+	assert.Empty(t, runner.CallsContaining(" pull"))
+}
+
+func TestPullUsesServicePlatform(t *testing.T) {
+	config := testConfig()
+	config.Platform = "linux/amd64"
+	app := config.Services["app"]
+	app.Platform = "linux/arm64"
+	config.Services["app"] = app
+
+	var composeFile string
+	provider, runner := newTestProvider(t, config, func(ctx context.Context, command string) ([]byte, error) {
+		if strings.Contains(command, " pull ") {
+			fields := strings.Fields(command)
+			for i, field := range fields {
+				if field == "-f" {
+					content, err := os.ReadFile(fields[i+1])
+					require.NoError(t, err)
+					composeFile = string(content)
+				}
+			}
+		}
+		return nil, nil
+	})
+
+	require.NoError(t, provider.Pull(context.Background()))
+
+	require.Len(t, runner.CallsContaining(" pull"), 1)
+	assert.Contains(t, composeFile, "  app:\n    image: app:latest\n    platform: linux/arm64\n")
+	assert.Contains(t, composeFile, "  db:\n    image: postgres:13\n    platform: linux/amd64\n")
+}
+
+func TestPullSkipsBuildOnlyServices(t *testing.T) {
+	config := testConfig()
+	config.Services["worker"] = ServiceConfig{Build: &BuildConfig{Context: "./worker"}}
+	config.Services["tools"] = ServiceConfig{ImageName: "tools", ImageTag: "local", PullPolicy: "never"}
+	config.Services["api"] = ServiceConfig{ImageName: "api", Build: &BuildConfig{Context: "./api"}, PullPolicy: "always"}
+	provider, runner := newTestProvider(t, config, nil)
+
+	pulled, skipped, err := provider.PullImages(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"api", "app", "db"}, pulled)
+	assert.Equal(t, []string{"tools", "worker"}, skipped)
+	calls := runner.CallsContaining(" pull")
+	require.Len(t, calls, 1)
+	assert.True(t, strings.HasSuffix(calls[0], " pull api app db"), calls[0])
+}
+
+func TestPullWithOnlySkippedServicesRunsNothing(t *testing.T) {
+	config := ComposeConfig{Services: map[string]ServiceConfig{
+		"worker": {Build: &BuildConfig{Context: "./worker"}},
+	}}
+	provider, runner := newTestProvider(t, config, nil)
+
+	pulled, skipped, err := provider.PullImages(context.Background())
+
+	require.NoError(t, err)
+	assert.Empty(t, pulled)
+	assert.Equal(t, []string{"worker"}, skipped)
+	assert.Empty(t, runner.CallsContaining(" pull"))
+}
+
+func TestPullMissingSkipsPresentImages(t *testing.T) {
+	config := ComposeConfig{Services: map[string]ServiceConfig{
+		"cached": {ImageName: "redis", ImageTag: "7", PullPolicy: "missing"},
+		"absent": {ImageName: "postgres", ImageTag: "16", PullPolicy: "missing"},
+	}}
+	provider, runner := newTestProvider(t, config, func(ctx context.Context, command string) ([]byte, error) {
+		if strings.HasPrefix(command, "docker image inspect") && !strings.HasSuffix(command, " redis:7") {
+			return []byte("Error: No such image"), errors.New("exit status 1")
+		}
+		return nil, nil
+	})
+
+	pulled, skipped, err := provider.PullImages(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"absent"}, pulled)
+	assert.Equal(t, []string{"cached"}, skipped)
+	assert.Len(t, runner.CallsContaining("docker image inspect"), 2)
+	calls := runner.CallsContaining(" pull")
+	require.Len(t, calls, 1)
+	assert.True(t, strings.HasSuffix(calls[0], " pull absent"), calls[0])
+}
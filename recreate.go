@@ -0,0 +1,57 @@
+package thirdpartyhosting
+
+import (
+	"context"
+	"fmt"
+)
+
+// RecreateService replaces one service's container, e.g. to pick up a new image, without
+// touching its dependencies, the rest of the stack or any named volumes
+func (p *DockerComposeProvider) RecreateService(ctx context.Context, serviceName string) error {
+	p.mu.RLock()
+	if !p.initialized {
+		p.mu.RUnlock()
+		return fmt.Errorf("provider not initialized")
+	}
+	config := p.config
+	p.mu.RUnlock()
+
+	serviceConfig, exists := config.Services[serviceName]
+	if !exists {
+		return fmt.Errorf("service %s not found", serviceName)
+	}
+	if !isServiceActive(config, serviceConfig) {
+		return fmt.Errorf("service %s is disabled: none of its profiles are active", serviceName)
+	}
+
+	// The service keeps its replica count, which would otherwise fall back to one
+	composeFile, scale, err := p.renderComposeFile(config, []string{serviceName})
+	if err != nil {
+		return err
+	}
+
+	upCtx, cancel := p.Timeouts.withTimeout(ctx, p.Timeouts.Start)
+	defer cancel()
+
+	args := append(append([]string{"up", "-d"}, scale...), "--no-deps", "--force-recreate", serviceName)
+	output, err := p.runCompose(upCtx, nil, composeArgs(config, composeFile, args...)...)
+	if err != nil {
+		return fmt.Errorf("failed to recreate service %s: %s, error: %w", serviceName, string(output), err)
+	}
+
+	// Only this service's container changed, so only its ID is refreshed
+	output, err = p.commands().Run(upCtx, "docker-compose", composeArgs(config, "", "ps", "-q", serviceName)...)
+	if err != nil {
+		return fmt.Errorf("failed to look up container for service %s: %w", serviceName, err)
+	}
+
+	p.mu.Lock()
+	if containerIDs := parseContainerIDs(output); len(containerIDs) > 0 {
+		p.containers[serviceName] = containerIDs
+	} else {
+		delete(p.containers, serviceName)
+	}
+	p.mu.Unlock()
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package thirdpartyhosting
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecreateService(t *testing.T) {
+	recreated := false
+	provider, runner := newTestProvider(t, testConfig(), func(ctx context.Context, command string) ([]byte, error) {
+		switch {
+		case strings.Contains(command, "--force-recreate"):
+			recreated = true
+		case strings.HasSuffix(command, "ps -q app") && recreated:
+			return []byte("new-app\n"), nil
+		case strings.HasSuffix(command, "ps -q app"):
+			return []byte("old-app\n"), nil
+		case strings.HasSuffix(command, "ps -q db"):
+			return []byte("db-id\n"), nil
+		}
+		return nil, nil
+	})
+	require.NoError(t, provider.Start(context.Background()))
+
+	require.NoError(t, provider.RecreateService(context.Background(), "app"))
+
+	calls := runner.CallsContaining("--force-recreate")
+	require.Len(t, calls, 1)
+	assert.True(t, strings.HasSuffix(calls[0], "up -d --no-deps --force-recreate app"), calls[0])
+	assert.NotContains(t, calls[0], " db")
+	assert.Empty(t, runner.CallsContaining("down"))
+	assert.Len(t, runner.CallsContaining("ps -q db"), 1, "only the recreated service is looked up again")
+
+	assert.Equal(t, "new-app", provider.GetContainerID("app"))
+	assert.Equal(t, "db-id", provider.GetContainerID("db"))
+}
+
+func TestRecreateServiceUnknown(t *testing.T) {
+	provider, runner := newTestProvider(t, testConfig(), nil)
+
+	assert.Error(t, provider.RecreateService(context.Background(), "cache"))
+	assert.Empty(t, runner.Calls())
+}
+
+func TestRecreateServiceDisabled(t *testing.T) {
+	config := testConfig()
+	config.Services["debug"] = ServiceConfig{ImageName: "busybox", Profiles: []string{"debug"}}
+	provider, runner := newTestProvider(t, config, nil)
+
+	assert.Error(t, provider.RecreateService(context.Background(), "debug"))
+	assert.Empty(t, runner.Calls())
+}
+
+func TestRecreateServiceKeepsLocalDeploy(t *testing.T) {
+	var command, composeFile string
+	provider, _ := newTestProvider(t, deployModeConfig(), captureUp(t, &command, &composeFile))
+	require.NoError(t, provider.Start(context.Background()))
+
+	require.NoError(t, provider.RecreateService(context.Background(), "worker"))
+
+	assert.True(t, strings.HasSuffix(command, " up -d --scale worker=3 --no-deps --force-recreate worker"), command)
+	assert.Contains(t, composeFile, "  worker:\n    image: worker\n    restart: on-failure:5\n")
+	assert.NotContains(t, composeFile, "deploy:")
+}
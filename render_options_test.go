@@ -0,0 +1,105 @@
+package thirdpartyhosting
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+// renderOptionsConfig is a small config exercising nested mappings, sequences and quoted values
+func renderOptionsConfig(opts RenderOptions) ComposeConfig {
+	return ComposeConfig{
+		RenderOptions: opts,
+		Services: map[string]ServiceConfig{
+			"app": {
+				ImageName:    "app",
+				ExposedPorts: []PortMapping{{HostPort: 8080, ContainerPort: 80}},
+				Command:      []string{"echo", "it's ready"},
+				Resources:    ResourceLimits{Memory: "256m"},
+			},
+		},
+	}
+}
+
+func TestRenderOptionsIndent(t *testing.T) {
+	twoSpaces, err := generateComposeContent(renderOptionsConfig(RenderOptions{}))
+	assert.NoError(t, err)
+	fourSpaces, err := generateComposeContent(renderOptionsConfig(RenderOptions{Indent: 4}))
+	assert.NoError(t, err)
+
+	assert.Equal(t, "version: \"3.4\"\n\n"+
+		"services:\n"+
+		"  app:\n"+
+		"    image: app\n"+
+		"    command: [\"echo\", \"it's ready\"]\n"+
+		"    ports:\n"+
+		"      - \"8080:80/tcp\"\n"+
+		"    deploy:\n"+
+		"      resources:\n"+
+		"        limits:\n"+
+		"          memory: 256m\n", twoSpaces)
+	assert.Equal(t, "version: \"3.4\"\n\n"+
+		"services:\n"+
+		"    app:\n"+
+		"        image: app\n"+
+		"        command: [\"echo\", \"it's ready\"]\n"+
+		"        ports:\n"+
+		"            - \"8080:80/tcp\"\n"+
+		"        deploy:\n"+
+		"            resources:\n"+
+		"                limits:\n"+
+		"                    memory: 256m\n", fourSpaces)
+}
+
+func TestRenderOptionsSingleQuotes(t *testing.T) {
+	content, err := generateComposeContent(renderOptionsConfig(RenderOptions{QuoteStyle: "single"}))
+
+	assert.NoError(t, err)
+	assert.Contains(t, content, "version: '3.4'\n")
+	assert.Contains(t, content, "    command: ['echo', 'it''s ready']\n")
+	assert.Contains(t, content, "      - '8080:80/tcp'\n")
+}
+
+func TestValidateConfigRenderOptions(t *testing.T) {
+	assert.Error(t, ValidateConfig(renderOptionsConfig(RenderOptions{QuoteStyle: "backtick"})))
+	assert.Error(t, ValidateConfig(renderOptionsConfig(RenderOptions{Indent: -2})))
+	assert.NoError(t, ValidateConfig(renderOptionsConfig(RenderOptions{Indent: 4, QuoteStyle: "single"})))
+}
+
+func TestRenderOptionsUseAnchors(t *testing.T) {
+	worker := func(command string) ServiceConfig {
+		return ServiceConfig{
+			ImageName:   "worker",
+			Command:     []string{command},
+			Environment: map[string]string{"QUEUE_URL": "amqp://queue", "LOG_LEVEL": "info"},
+			Resources:   ResourceLimits{Memory: "512m", CPUShare: "0.5"},
+		}
+	}
+	config := ComposeConfig{
+		Services: map[string]ServiceConfig{
+			"emails":  worker("emails"),
+			"reports": worker("reports"),
+			"web":     {ImageName: "web", Environment: map[string]string{"LOG_LEVEL": "debug"}},
+		},
+	}
+
+	expanded, err := generateComposeContent(config)
+	assert.NoError(t, err)
+	config.RenderOptions.UseAnchors = true
+	anchored, err := generateComposeContent(config)
+	assert.NoError(t, err)
+
+	assert.Contains(t, anchored, "    environment: &emails-environment\n")
+	assert.Contains(t, anchored, "    environment: *emails-environment\n")
+	assert.Contains(t, anchored, "    deploy: &emails-deploy\n")
+	assert.Contains(t, anchored, "    deploy: *emails-deploy\n")
+	assert.Equal(t, 1, strings.Count(anchored, "*emails-environment"), "web's distinct environment must not be aliased")
+	assert.Less(t, len(anchored), len(expanded))
+
+	var anchoredDoc, expandedDoc map[string]interface{}
+	assert.NoError(t, yaml.Unmarshal([]byte(anchored), &anchoredDoc))
+	assert.NoError(t, yaml.Unmarshal([]byte(expanded), &expandedDoc))
+	assert.Equal(t, expandedDoc, anchoredDoc)
+}
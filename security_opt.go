@@ -0,0 +1,40 @@
+package thirdpartyhosting
+
+import "strings"
+
+// SecurityOptNoNewPrivileges prevents processes in the container from gaining new privileges
+const SecurityOptNoNewPrivileges = "no-new-privileges:true"
+
+// SecurityOptSeccomp returns the security_opt entry applying a seccomp profile file,
+// or "unconfined" to disable seccomp filtering
+func SecurityOptSeccomp(profile string) string {
+	return "seccomp=" + profile
+}
+
+// SecurityOptAppArmor returns the security_opt entry applying an AppArmor profile
+func SecurityOptAppArmor(profile string) string {
+	return "apparmor=" + profile
+}
+
+// seccompProfilePath returns the profile file referenced by a seccomp security_opt
+// entry, or "" when the entry is not a seccomp profile file
+func seccompProfilePath(opt string) string {
+	for _, prefix := range []string{"seccomp=", "seccomp:"} {
+		if strings.HasPrefix(opt, prefix) {
+			path := strings.TrimPrefix(opt, prefix)
+			if path == "unconfined" {
+				return ""
+			}
+			return path
+		}
+	}
+	return ""
+}
+
+// resolveSecurityOpt resolves a relative seccomp profile path against baseDir
+func resolveSecurityOpt(baseDir, opt string) string {
+	if path := seccompProfilePath(opt); path != "" {
+		return SecurityOptSeccomp(resolveRelativePath(baseDir, path))
+	}
+	return opt
+}
@@ -0,0 +1,57 @@
+package thirdpartyhosting
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateComposeContentSecurityOpt(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "seccomp.json"), []byte("{}"), 0644))
+
+	config := ComposeConfig{
+		BaseDir: dir,
+		Services: map[string]ServiceConfig{
+			"api": {
+				ImageName: "api",
+				SecurityOpt: []string{
+					SecurityOptNoNewPrivileges,
+					SecurityOptSeccomp("seccomp.json"),
+					SecurityOptAppArmor("docker-default"),
+				},
+			},
+		},
+	}
+
+	content, err := generateComposeContent(config)
+
+	assert.NoError(t, ValidateConfig(config))
+	assert.NoError(t, err)
+	assert.Contains(t, content, "    security_opt:\n"+
+		"      - no-new-privileges:true\n"+
+		"      - seccomp="+filepath.Join(dir, "seccomp.json")+"\n"+
+		"      - apparmor=docker-default\n")
+}
+
+func TestValidateConfigSeccompProfile(t *testing.T) {
+	missing := ComposeConfig{
+		BaseDir: t.TempDir(),
+		Services: map[string]ServiceConfig{
+			"api": {ImageName: "api", SecurityOpt: []string{SecurityOptSeccomp("missing.json")}},
+		},
+	}
+	err := ValidateConfig(missing)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "seccomp profile missing.json")
+
+	unconfined := ComposeConfig{
+		Services: map[string]ServiceConfig{
+			"api": {ImageName: "api", SecurityOpt: []string{SecurityOptSeccomp("unconfined")}},
+		},
+	}
+	assert.NoError(t, ValidateConfig(unconfined))
+}
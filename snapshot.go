@@ -0,0 +1,106 @@
+package thirdpartyhosting
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// StackSnapshot captures the state of every service at a point in time. It marshals
+// to JSON so it can be saved as a debugging artifact, e.g. from a failed CI run.
+type StackSnapshot struct {
+	Project    string                     `json:"project"`
+	TakenAt    time.Time                  `json:"taken_at"`
+	ConfigHash string                     `json:"config_hash"` // sha256 of the compose file Start deploys
+	Services   map[string]ServiceSnapshot `json:"services"`
+}
+
+// ServiceSnapshot is the state of a single service within a StackSnapshot
+type ServiceSnapshot struct {
+	ContainerID string `json:"container_id,omitempty"`
+	Status      string `json:"status"`           // as reported by Status, e.g. "running" or "not_found"
+	Health      string `json:"health,omitempty"` // empty when the service has no healthcheck
+	ExitCode    int    `json:"exit_code"`
+	Logs        string `json:"logs,omitempty"`
+	Error       string `json:"error,omitempty"` // why part of the snapshot could not be gathered
+}
+
+// Snapshot gathers each service's status, health and last logTailLines lines of logs,
+// along with a hash of the configuration. Logs are skipped when logTailLines is 0 or less.
+// Failures for a single service are recorded in its Error field rather than aborting the snapshot.
+func (p *DockerComposeProvider) Snapshot(ctx context.Context, logTailLines int) (StackSnapshot, error) {
+	p.mu.RLock()
+	if !p.initialized {
+		p.mu.RUnlock()
+		return StackSnapshot{}, fmt.Errorf("provider not initialized")
+	}
+	config := p.config
+	p.mu.RUnlock()
+
+	// Hash the file Start deploys, not config as given
+	deployed, _ := p.deployedConfig(config, nil)
+	content, err := generateComposeContent(deployed)
+	if err != nil {
+		return StackSnapshot{}, fmt.Errorf("failed to generate compose content: %w", err)
+	}
+	hash := sha256.Sum256([]byte(content))
+
+	if err := p.updateContainerIDs(ctx); err != nil {
+		return StackSnapshot{}, err
+	}
+
+	snapshot := StackSnapshot{
+		Project:    normalizeProjectName(config.ProjectName),
+		TakenAt:    time.Now().UTC(),
+		ConfigHash: hex.EncodeToString(hash[:]),
+		Services:   make(map[string]ServiceSnapshot, len(config.Services)),
+	}
+
+	for service, serviceConfig := range config.Services {
+		if !isServiceActive(config, serviceConfig) {
+			snapshot.Services[service] = ServiceSnapshot{Status: "disabled"}
+			continue
+		}
+
+		containerID := p.GetContainerID(service)
+		if containerID == "" {
+			snapshot.Services[service] = ServiceSnapshot{Status: "not_found"}
+			continue
+		}
+
+		snapshot.Services[service] = p.snapshotService(ctx, containerID, logTailLines)
+	}
+
+	return snapshot, nil
+}
+
+// snapshotService inspects a single container and tails its logs
+func (p *DockerComposeProvider) snapshotService(ctx context.Context, containerID string, logTailLines int) ServiceSnapshot {
+	snapshot := ServiceSnapshot{ContainerID: containerID}
+
+	state, err := p.inspectState(ctx, containerID)
+	if err != nil {
+		snapshot.Status = "error"
+		snapshot.Error = err.Error()
+		return snapshot
+	}
+	snapshot.Status = state.Status
+	snapshot.ExitCode = state.ExitCode
+	if state.Health != nil {
+		snapshot.Health = state.Health.Status
+	}
+
+	if logTailLines > 0 {
+		output, err := p.commands().Run(ctx, "docker", "logs", "--tail", strconv.Itoa(logTailLines), containerID)
+		if err != nil {
+			snapshot.Error = fmt.Sprintf("failed to get logs: %s, error: %v", string(output), err)
+		} else {
+			snapshot.Logs = string(output)
+		}
+	}
+
+	return snapshot
+}
@@ -0,0 +1,102 @@
+package thirdpartyhosting
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshot(t *testing.T) {
+	config := testConfig()
+	app := config.Services["app"]
+	app.HealthCheck = &HealthCheck{Test: []string{"CMD", "true"}}
+	config.Services["app"] = app
+
+	statuses := healthHandler(map[string]string{"app": "unhealthy", "db": "exited"})
+	provider, runner := newTestProvider(t, config, func(ctx context.Context, command string) ([]byte, error) {
+		switch command {
+		case "docker logs --tail 2 id-app":
+			return []byte("connecting to db\nconnection refused\n"), nil
+		case "docker logs --tail 2 id-db":
+			return nil, errors.New("exit status 1")
+		}
+		return statuses(ctx, command)
+	})
+
+	snapshot, err := provider.Snapshot(context.Background(), 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "test-project", snapshot.Project)
+	assert.Len(t, snapshot.ConfigHash, 64)
+	assert.False(t, snapshot.TakenAt.IsZero())
+
+	assert.Equal(t, ServiceSnapshot{
+		ContainerID: "id-app",
+		Status:      "running",
+		Health:      "unhealthy",
+		Logs:        "connecting to db\nconnection refused\n",
+	}, snapshot.Services["app"])
+
+	db := snapshot.Services["db"]
+	assert.Equal(t, "exited", db.Status)
+	assert.Equal(t, 1, db.ExitCode)
+	assert.Contains(t, db.Error, "failed to get logs")
+	assert.Len(t, runner.CallsContaining("docker logs --tail 2"), 2)
+
+	data, err := json.Marshal(snapshot)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"health":"unhealthy"`)
+}
+
+func TestSnapshotConfigHashTracksConfig(t *testing.T) {
+	handler := healthHandler(map[string]string{"app": "running", "db": "running"})
+	provider, _ := newTestProvider(t, testConfig(), handler)
+	first, err := provider.Snapshot(context.Background(), 0)
+	assert.NoError(t, err)
+
+	changed := testConfig()
+	db := changed.Services["db"]
+	db.ImageTag = "16"
+	changed.Services["db"] = db
+	other, _ := newTestProvider(t, changed, handler)
+	second, err := other.Snapshot(context.Background(), 0)
+	assert.NoError(t, err)
+
+	assert.NotEqual(t, first.ConfigHash, second.ConfigHash)
+	assert.Empty(t, second.Services["app"].Logs)
+}
+
+func TestSnapshotConfigHashMatchesDeployedFile(t *testing.T) {
+	config := testConfig()
+	app := config.Services["app"]
+	app.Deploy = &DeployConfig{RestartPolicy: &DeployRestartPolicy{Condition: "on-failure"}}
+	config.Services["app"] = app
+
+	provider, _ := newTestProvider(t, config, healthHandler(map[string]string{"app": "running", "db": "running"}))
+	provider.fileVersion = "3.8"
+
+	snapshot, err := provider.Snapshot(context.Background(), 0)
+	assert.NoError(t, err)
+
+	// The hash covers the detected version and the local deploy translation Start applies
+	composeFile, _, err := provider.renderComposeFile(config, nil)
+	assert.NoError(t, err)
+	defer os.RemoveAll(filepath.Dir(composeFile))
+	deployed, err := os.ReadFile(composeFile)
+	assert.NoError(t, err)
+	hash := sha256.Sum256(deployed)
+	assert.Equal(t, hex.EncodeToString(hash[:]), snapshot.ConfigHash)
+}
+
+func TestSnapshotNotInitialized(t *testing.T) {
+	_, err := NewDockerComposeProvider().Snapshot(context.Background(), 10)
+
+	assert.Error(t, err)
+}
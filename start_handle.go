@@ -0,0 +1,75 @@
+package thirdpartyhosting
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// StartHandle tracks a Start running in the background
+type StartHandle struct {
+	provider *DockerComposeProvider
+	cancel   context.CancelFunc
+	done     chan struct{}
+	err      error
+	mu       sync.Mutex
+}
+
+// StartAsync kicks off Start in the background and returns a handle to track or abort it
+func (p *DockerComposeProvider) StartAsync(ctx context.Context) (*StartHandle, error) {
+	p.mu.RLock()
+	initialized := p.initialized
+	p.mu.RUnlock()
+
+	if !initialized {
+		return nil, fmt.Errorf("provider not initialized")
+	}
+
+	startCtx, cancel := context.WithCancel(ctx)
+	h := &StartHandle{
+		provider: p,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+
+	go func() {
+		defer close(h.done)
+		err := p.Start(startCtx)
+
+		h.mu.Lock()
+		h.err = err
+		h.mu.Unlock()
+	}()
+
+	return h, nil
+}
+
+// Done returns a channel that is closed once the background Start has finished
+func (h *StartHandle) Done() <-chan struct{} {
+	return h.done
+}
+
+// Err returns the result of the background Start, or nil while it is still running
+func (h *StartHandle) Err() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.err
+}
+
+// Cancel aborts the background Start and tears down any services it had already started.
+// It is a no-op if Start already completed successfully.
+func (h *StartHandle) Cancel() error {
+	h.cancel()
+	<-h.done
+
+	if h.Err() == nil {
+		return nil
+	}
+
+	if err := h.provider.Stop(context.Background()); err != nil {
+		return fmt.Errorf("failed to tear down partially started services: %w", err)
+	}
+
+	return nil
+}
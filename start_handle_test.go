@@ -0,0 +1,59 @@
+package thirdpartyhosting
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartAsyncCompletes(t *testing.T) {
+	provider, runner := newTestProvider(t, testConfig(), nil)
+
+	handle, err := provider.StartAsync(context.Background())
+	require.NoError(t, err)
+
+	select {
+	case <-handle.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("start did not complete")
+	}
+
+	assert.NoError(t, handle.Err())
+	assert.NoError(t, handle.Cancel())
+	assert.Len(t, runner.CallsContaining("up -d"), 1)
+	assert.Empty(t, runner.CallsContaining(" down"))
+}
+
+func TestStartAsyncCancel(t *testing.T) {
+	started := make(chan struct{})
+	provider, runner := newTestProvider(t, testConfig(), func(ctx context.Context, command string) ([]byte, error) {
+		if strings.Contains(command, "up -d") {
+			close(started)
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return nil, nil
+	})
+
+	handle, err := provider.StartAsync(context.Background())
+	require.NoError(t, err)
+	<-started
+
+	assert.Nil(t, handle.Err())
+	assert.NoError(t, handle.Cancel())
+
+	assert.Error(t, handle.Err())
+	assert.Len(t, runner.CallsContaining(" down"), 1)
+}
+
+func TestStartAsyncNotInitialized(t *testing.T) {
+	provider := NewDockerComposeProvider()
+
+	_, err := provider.StartAsync(context.Background())
+
+	assert.Error(t, err)
+}
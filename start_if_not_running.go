@@ -0,0 +1,46 @@
+package thirdpartyhosting
+
+import (
+	"context"
+	"sort"
+)
+
+// StartIfNotRunning starts only the services that aren't already running and is a
+// no-op when the whole stack is up. It reports which services it started and which
+// were already running; services in inactive profiles appear in neither list.
+func (p *DockerComposeProvider) StartIfNotRunning(ctx context.Context) (started, alreadyRunning []string, err error) {
+	statuses, err := p.Status(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for service, status := range statuses {
+		switch status {
+		case "disabled":
+		case "running", "healthy", "unhealthy", "starting":
+			// A running container with a healthcheck reports its health instead
+			alreadyRunning = append(alreadyRunning, service)
+		default:
+			started = append(started, service)
+		}
+	}
+	sort.Strings(started)
+	sort.Strings(alreadyRunning)
+
+	if len(started) == 0 {
+		return nil, alreadyRunning, nil
+	}
+
+	p.mu.RLock()
+	config := p.config
+	p.mu.RUnlock()
+
+	if err := p.runPreStart(ctx); err != nil {
+		return nil, alreadyRunning, err
+	}
+	if err := p.up(ctx, config, started...); err != nil {
+		return nil, alreadyRunning, err
+	}
+
+	return started, alreadyRunning, nil
+}
@@ -0,0 +1,64 @@
+package thirdpartyhosting
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStartIfNotRunningAllRunning(t *testing.T) {
+	provider, runner := newTestProvider(t, testConfig(), healthHandler(map[string]string{
+		"app": "running",
+		"db":  "running",
+	}))
+
+	started, alreadyRunning, err := provider.StartIfNotRunning(context.Background())
+
+	assert.NoError(t, err)
+	assert.Empty(t, started)
+	assert.Equal(t, []string{"app", "db"}, alreadyRunning)
+	assert.Empty(t, runner.CallsContaining(" up "))
+}
+
+func TestStartIfNotRunningPartial(t *testing.T) {
+	provider, runner := newTestProvider(t, testConfig(), healthHandler(map[string]string{
+		"app": "exited",
+		"db":  "running",
+	}))
+
+	started, alreadyRunning, err := provider.StartIfNotRunning(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"app"}, started)
+	assert.Equal(t, []string{"db"}, alreadyRunning)
+
+	ups := runner.CallsContaining(" up -d")
+	if assert.Len(t, ups, 1) {
+		assert.True(t, strings.HasSuffix(ups[0], "up -d app"), ups[0])
+	}
+}
+
+func TestStartIfNotRunningWithHealthCheck(t *testing.T) {
+	config := testConfig()
+	app := config.Services["app"]
+	app.HealthCheck = &HealthCheck{Test: []string{"CMD", "true"}}
+	config.Services["app"] = app
+
+	provider, runner := newTestProvider(t, config, healthHandler(map[string]string{
+		"app": "healthy",
+		"db":  "exited",
+	}))
+
+	started, alreadyRunning, err := provider.StartIfNotRunning(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"db"}, started)
+	assert.Equal(t, []string{"app"}, alreadyRunning)
+
+	ups := runner.CallsContaining(" up -d")
+	if assert.Len(t, ups, 1) {
+		assert.True(t, strings.HasSuffix(ups[0], "up -d db"), ups[0])
+	}
+}
@@ -0,0 +1,96 @@
+package thirdpartyhosting
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// StartOrdered starts services one at a time in dependency order, waiting for each to be
+// healthy (or running, if it has no healthcheck) before starting the services that depend
+// on it. Each service gets up to timeout to become ready.
+func (p *DockerComposeProvider) StartOrdered(ctx context.Context, timeout time.Duration) error {
+	p.mu.RLock()
+	if !p.initialized {
+		p.mu.RUnlock()
+		return fmt.Errorf("provider not initialized")
+	}
+	config := p.config
+	p.mu.RUnlock()
+
+	order, err := startOrder(config)
+	if err != nil {
+		return err
+	}
+
+	if err := p.runPreStart(ctx); err != nil {
+		return err
+	}
+
+	for _, service := range order {
+		if err := p.up(ctx, config, "--no-deps", service); err != nil {
+			return fmt.Errorf("failed to start service %s: %w", service, err)
+		}
+		if err := p.waitForServices(ctx, config, []string{service}, timeout); err != nil {
+			return fmt.Errorf("service %s did not become ready: %w", service, err)
+		}
+	}
+
+	return nil
+}
+
+// startOrder returns the active services sorted so every service comes after its
+// dependencies. Services with no ordering constraint between them are sorted by name.
+func startOrder(config ComposeConfig) ([]string, error) {
+	remaining := make(map[string][]string)
+	for service, serviceConfig := range config.Services {
+		if !isServiceActive(config, serviceConfig) {
+			continue
+		}
+		deps := serviceDependencies(serviceConfig)
+		for _, dep := range deps {
+			if _, exists := config.Services[dep]; !exists {
+				return nil, fmt.Errorf("service %s depends on unknown service %s", service, dep)
+			}
+		}
+		remaining[service] = deps
+	}
+
+	var order []string
+	for len(remaining) > 0 {
+		var ready []string
+		for service, deps := range remaining {
+			if !dependsOnAny(deps, remaining) {
+				ready = append(ready, service)
+			}
+		}
+		if len(ready) == 0 {
+			cycle := make([]string, 0, len(remaining))
+			for service := range remaining {
+				cycle = append(cycle, service)
+			}
+			sort.Strings(cycle)
+			return nil, fmt.Errorf("dependency cycle between services: %s", strings.Join(cycle, ", "))
+		}
+
+		sort.Strings(ready)
+		for _, service := range ready {
+			order = append(order, service)
+			delete(remaining, service)
+		}
+	}
+
+	return order, nil
+}
+
+// dependsOnAny reports whether any of deps is still waiting to be ordered
+func dependsOnAny(deps []string, remaining map[string][]string) bool {
+	for _, dep := range deps {
+		if _, pending := remaining[dep]; pending {
+			return true
+		}
+	}
+	return false
+}
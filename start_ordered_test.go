@@ -0,0 +1,99 @@
+package thirdpartyhosting
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// threeTierConfig returns a web -> api -> db dependency chain
+func threeTierConfig() ComposeConfig {
+	return ComposeConfig{
+		ProjectName: "tiers",
+		Services: map[string]ServiceConfig{
+			"web": {ImageName: "web", DependsOn: []string{"api"}},
+			"api": {ImageName: "api", DependsOn: []string{"db"}, HealthCheck: &HealthCheck{Test: []string{"CMD", "true"}}},
+			"db":  {ImageName: "postgres", HealthCheck: &HealthCheck{Test: []string{"CMD", "pg_isready"}}},
+		},
+	}
+}
+
+func TestStartOrder(t *testing.T) {
+	order, err := startOrder(threeTierConfig())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"db", "api", "web"}, order)
+}
+
+func TestStartOrderCycle(t *testing.T) {
+	config := threeTierConfig()
+	db := config.Services["db"]
+	db.DependsOn = []string{"web"}
+	config.Services["db"] = db
+
+	_, err := startOrder(config)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "dependency cycle")
+}
+
+func TestStartOrderedGatesOnReadiness(t *testing.T) {
+	var mu sync.Mutex
+	started := make(map[string]bool)
+	inspections := make(map[string]int)
+	var events []string
+
+	provider, _ := newTestProvider(t, threeTierConfig(), func(ctx context.Context, command string) ([]byte, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		for _, service := range []string{"web", "api", "db"} {
+			switch {
+			case strings.HasSuffix(command, "up -d --no-deps "+service):
+				started[service] = true
+				events = append(events, "start "+service)
+				return nil, nil
+			case strings.HasSuffix(command, "ps -q "+service):
+				if started[service] {
+					return []byte("id-" + service), nil
+				}
+				return nil, nil
+			case strings.HasSuffix(command, " id-"+service):
+				// Services with a healthcheck report starting on the first inspection
+				inspections[service]++
+				if service != "web" && inspections[service] == 1 {
+					return []byte("[" + inspectEntry("id-"+service, "starting") + "]"), nil
+				}
+				if service == "web" {
+					return []byte("[" + inspectEntry("id-"+service, "running") + "]"), nil
+				}
+				events = append(events, service+" healthy")
+				return []byte("[" + inspectEntry("id-"+service, "healthy") + "]"), nil
+			}
+		}
+		return nil, nil
+	})
+	provider.pollInterval = time.Millisecond
+
+	err := provider.StartOrdered(context.Background(), time.Second)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"start db", "db healthy", "start api", "api healthy", "start web"}, events)
+}
+
+func TestStartOrderedStopsWhenServiceNeverReady(t *testing.T) {
+	provider, runner := newTestProvider(t, threeTierConfig(), healthHandler(map[string]string{
+		"db": "unhealthy",
+	}))
+	provider.pollInterval = time.Millisecond
+
+	err := provider.StartOrdered(context.Background(), 20*time.Millisecond)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "service db did not become ready")
+	assert.Empty(t, runner.CallsContaining("--no-deps api"))
+}
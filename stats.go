@@ -0,0 +1,120 @@
+package thirdpartyhosting
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ContainerStats is a container's resource usage as reported by docker stats
+type ContainerStats struct {
+	Container string `json:"Container"` // the ID docker stats was asked about
+	Name      string `json:"Name"`
+	CPUPerc   string `json:"CPUPerc"`  // e.g. "0.52%"
+	MemUsage  string `json:"MemUsage"` // e.g. "21.3MiB / 1.94GiB"
+	MemPerc   string `json:"MemPerc"`
+	NetIO     string `json:"NetIO"`
+	BlockIO   string `json:"BlockIO"`
+	PIDs      string `json:"PIDs"`
+}
+
+// terminalEscape matches the screen-clearing sequences docker stats writes between refreshes
+var terminalEscape = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+
+// StreamStats follows docker stats for the running services and sends one snapshot per
+// refresh, keyed by service name. The channel is closed, and docker stats stopped, when
+// ctx is cancelled or the stats process exits.
+func (p *DockerComposeProvider) StreamStats(ctx context.Context) (<-chan map[string]ContainerStats, error) {
+	p.mu.RLock()
+	if !p.initialized {
+		p.mu.RUnlock()
+		return nil, fmt.Errorf("provider not initialized")
+	}
+	p.mu.RUnlock()
+
+	if err := p.updateContainerIDs(ctx); err != nil {
+		return nil, err
+	}
+
+	services := make(map[string]string)
+	p.mu.RLock()
+	for service, containerIDs := range p.containers {
+		// Frames are keyed by service, so a scaled service reports its first container
+		if len(containerIDs) > 0 {
+			services[containerIDs[0]] = service
+		}
+	}
+	p.mu.RUnlock()
+
+	if len(services) == 0 {
+		return nil, fmt.Errorf("no running containers to collect stats for")
+	}
+
+	ids := make([]string, 0, len(services))
+	for containerID := range services {
+		ids = append(ids, containerID)
+	}
+	sort.Strings(ids)
+
+	statsCtx, cancel := context.WithCancel(ctx)
+	reader, writer := io.Pipe()
+	go func() {
+		args := append([]string{"stats", "--format", "{{json .}}"}, ids...)
+		writer.CloseWithError(p.commands().Stream(statsCtx, nil, writer, "docker", args...))
+	}()
+
+	snapshots := make(chan map[string]ContainerStats)
+	go func() {
+		defer close(snapshots)
+		defer reader.Close()
+		defer cancel()
+
+		frame := make(map[string]ContainerStats)
+		send := func() bool {
+			if len(frame) == 0 {
+				return true
+			}
+			select {
+			case snapshots <- frame:
+				frame = make(map[string]ContainerStats)
+				return true
+			case <-statsCtx.Done():
+				return false
+			}
+		}
+
+		scanner := bufio.NewScanner(reader)
+		for scanner.Scan() {
+			line := strings.TrimSpace(terminalEscape.ReplaceAllString(scanner.Text(), ""))
+			if line == "" {
+				continue
+			}
+
+			var stats ContainerStats
+			if err := json.Unmarshal([]byte(line), &stats); err != nil {
+				continue
+			}
+			service, ok := services[stats.Container]
+			if !ok {
+				continue
+			}
+
+			// A container seen twice means a new refresh started without the previous one completing
+			if _, seen := frame[service]; seen && !send() {
+				return
+			}
+			frame[service] = stats
+			if len(frame) == len(services) && !send() {
+				return
+			}
+		}
+		send()
+	}()
+
+	return snapshots, nil
+}
@@ -0,0 +1,101 @@
+package thirdpartyhosting
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func statsLine(containerID, cpu string) string {
+	return fmt.Sprintf(`{"BlockIO":"0B / 0B","CPUPerc":%q,"Container":%q,"ID":%q,"MemPerc":"1.00%%","MemUsage":"20MiB / 2GiB","Name":"test-project-%s-1","NetIO":"1kB / 0B","PIDs":"3"}`,
+		cpu, containerID, containerID, strings.TrimPrefix(containerID, "id-"))
+}
+
+// statsHandler answers ps -q with an ID per service and docker stats with output
+func statsHandler(output string) func(ctx context.Context, command string) ([]byte, error) {
+	return func(ctx context.Context, command string) ([]byte, error) {
+		for _, service := range []string{"app", "db"} {
+			if strings.HasSuffix(command, "ps -q "+service) {
+				return []byte("id-" + service + "\n"), nil
+			}
+		}
+		if strings.HasPrefix(command, "docker stats") {
+			return []byte(output), nil
+		}
+		return nil, nil
+	}
+}
+
+func TestStreamStats(t *testing.T) {
+	output := "\x1b[2J\x1b[H" + statsLine("id-app", "0.50%") + "\n" + statsLine("id-db", "1.25%") + "\n" +
+		"\x1b[2J\x1b[H" + statsLine("id-app", "0.75%") + "\n" + statsLine("id-db", "2.00%") + "\n"
+	provider, runner := newTestProvider(t, testConfig(), statsHandler(output))
+
+	stream, err := provider.StreamStats(context.Background())
+	require.NoError(t, err)
+
+	var snapshots []map[string]ContainerStats
+	for snapshot := range stream {
+		snapshots = append(snapshots, snapshot)
+	}
+
+	require.Len(t, snapshots, 2)
+	assert.Equal(t, "0.50%", snapshots[0]["app"].CPUPerc)
+	assert.Equal(t, "1.25%", snapshots[0]["db"].CPUPerc)
+	assert.Equal(t, "2.00%", snapshots[1]["db"].CPUPerc)
+	assert.Equal(t, "20MiB / 2GiB", snapshots[1]["app"].MemUsage)
+	assert.Equal(t, []string{"docker stats --format {{json .}} id-app id-db"}, runner.CallsContaining("docker stats"))
+}
+
+func TestStreamStatsSplitsFramesOnRepeatedContainer(t *testing.T) {
+	// db stopped reporting, so each app line starts a new snapshot
+	output := statsLine("id-app", "0.50%") + "\n" + statsLine("id-app", "0.60%") + "\n"
+	provider, _ := newTestProvider(t, testConfig(), statsHandler(output))
+
+	stream, err := provider.StreamStats(context.Background())
+	require.NoError(t, err)
+
+	var cpu []string
+	for snapshot := range stream {
+		assert.Len(t, snapshot, 1)
+		cpu = append(cpu, snapshot["app"].CPUPerc)
+	}
+	assert.Equal(t, []string{"0.50%", "0.60%"}, cpu)
+}
+
+func TestStreamStatsClosesOnCancel(t *testing.T) {
+	provider, _ := newTestProvider(t, testConfig(), func(ctx context.Context, command string) ([]byte, error) {
+		if strings.HasSuffix(command, "ps -q app") {
+			return []byte("id-app\n"), nil
+		}
+		if strings.HasPrefix(command, "docker stats") {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return nil, nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := provider.StreamStats(ctx)
+	require.NoError(t, err)
+
+	cancel()
+	select {
+	case _, open := <-stream:
+		assert.False(t, open)
+	case <-time.After(time.Second):
+		t.Fatal("stats channel was not closed after cancellation")
+	}
+}
+
+func TestStreamStatsNothingRunning(t *testing.T) {
+	provider, _ := newTestProvider(t, testConfig(), nil)
+
+	_, err := provider.StreamStats(context.Background())
+	assert.Error(t, err)
+}
@@ -0,0 +1,27 @@
+package thirdpartyhosting
+
+import (
+	"context"
+	"time"
+)
+
+// Timeouts bounds how long each provider operation may run. A zero duration falls back
+// to Default, and a zero Default leaves the operation bounded only by the caller's context.
+type Timeouts struct {
+	Default time.Duration
+	Start   time.Duration // docker-compose up, excluding PreStart and PullOnStart's pull
+	Stop    time.Duration // docker-compose down, excluding PostStop
+	Status  time.Duration // ps and inspect calls made by Status
+	Pull    time.Duration // docker-compose pull, including the one run by PullOnStart
+}
+
+// withTimeout derives the context for an operation from its configured timeout
+func (t Timeouts) withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		timeout = t.Default
+	}
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
@@ -0,0 +1,85 @@
+package thirdpartyhosting
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// deadlineRecorder records how much time each docker-compose subcommand was given
+type deadlineRecorder struct {
+	mu        sync.Mutex
+	remaining map[string]time.Duration // subcommand -> time left when it ran; -1 without a deadline
+}
+
+func (r *deadlineRecorder) handler(ctx context.Context, command string) ([]byte, error) {
+	left := time.Duration(-1)
+	if deadline, ok := ctx.Deadline(); ok {
+		left = time.Until(deadline)
+	}
+
+	for _, subcommand := range []string{"up -d", "down", "ps -q", "pull"} {
+		if strings.Contains(command, " "+subcommand) {
+			r.mu.Lock()
+			r.remaining[subcommand] = left
+			r.mu.Unlock()
+		}
+	}
+	return nil, nil
+}
+
+// assertTimeout checks a subcommand ran with roughly the expected time left
+func (r *deadlineRecorder) assertTimeout(t *testing.T, subcommand string, expected time.Duration) {
+	t.Helper()
+
+	r.mu.Lock()
+	left, ok := r.remaining[subcommand]
+	r.mu.Unlock()
+
+	assert.True(t, ok, "%s was not run", subcommand)
+	assert.True(t, left <= expected && left > expected-time.Second, "%s ran with %v left, want about %v", subcommand, left, expected)
+}
+
+func TestTimeoutsPerOperation(t *testing.T) {
+	recorder := &deadlineRecorder{remaining: make(map[string]time.Duration)}
+	provider, _ := newTestProvider(t, testConfig(), recorder.handler)
+	provider.Timeouts = Timeouts{Start: time.Minute, Stop: 2 * time.Minute, Status: 5 * time.Second, Pull: 10 * time.Minute}
+	provider.PullOnStart = true
+	ctx := context.Background()
+
+	assert.NoError(t, provider.Start(ctx))
+	recorder.assertTimeout(t, "pull", 10*time.Minute)
+	recorder.assertTimeout(t, "up -d", time.Minute)
+
+	_, err := provider.Status(ctx)
+	assert.NoError(t, err)
+	recorder.assertTimeout(t, "ps -q", 5*time.Second)
+
+	assert.NoError(t, provider.Stop(ctx))
+	recorder.assertTimeout(t, "down", 2*time.Minute)
+}
+
+func TestTimeoutsFallBackToDefault(t *testing.T) {
+	recorder := &deadlineRecorder{remaining: make(map[string]time.Duration)}
+	provider, _ := newTestProvider(t, testConfig(), recorder.handler)
+	provider.Timeouts = Timeouts{Default: 30 * time.Second, Stop: time.Minute}
+
+	assert.NoError(t, provider.Pull(context.Background()))
+	assert.NoError(t, provider.Stop(context.Background()))
+
+	recorder.assertTimeout(t, "pull", 30*time.Second)
+	recorder.assertTimeout(t, "down", time.Minute)
+}
+
+func TestTimeoutsUnset(t *testing.T) {
+	recorder := &deadlineRecorder{remaining: make(map[string]time.Duration)}
+	provider, _ := newTestProvider(t, testConfig(), recorder.handler)
+
+	assert.NoError(t, provider.Pull(context.Background()))
+
+	assert.Equal(t, time.Duration(-1), recorder.remaining["pull"])
+}
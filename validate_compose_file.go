@@ -0,0 +1,30 @@
+package thirdpartyhosting
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ValidateComposeFile renders config and asks docker-compose config -q to check it, which
+// catches schema problems ValidateConfig doesn't know about. Compose's own error text is
+// included in the returned error. The provider does not need to be initialized.
+func (p *DockerComposeProvider) ValidateComposeFile(ctx context.Context, config ComposeConfig) error {
+	composeFile, _, err := p.renderComposeFile(config, nil)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(filepath.Dir(composeFile))
+
+	output, err := p.commands().Run(ctx, "docker-compose", composeArgs(config, composeFile, "config", "-q")...)
+	if err != nil {
+		if message := strings.TrimSpace(string(output)); message != "" {
+			return fmt.Errorf("compose rejected the configuration: %s", message)
+		}
+		return fmt.Errorf("failed to run docker-compose config: %w", err)
+	}
+
+	return nil
+}
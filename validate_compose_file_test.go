@@ -0,0 +1,56 @@
+package thirdpartyhosting
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateComposeFile(t *testing.T) {
+	runner := &fakeRunner{}
+	provider := NewDockerComposeProvider()
+	provider.runner = runner
+
+	err := provider.ValidateComposeFile(context.Background(), testConfig())
+
+	require.NoError(t, err)
+	calls := runner.CallsContaining("config -q")
+	require.Len(t, calls, 1)
+	assert.Contains(t, calls[0], "docker-compose -p test-project -f ")
+}
+
+func TestValidateComposeFileReturnsComposeError(t *testing.T) {
+	config := testConfig()
+	app := config.Services["app"]
+	app.RestartPolicy = "sometimes"
+	config.Services["app"] = app
+
+	provider := NewDockerComposeProvider()
+	provider.runner = &fakeRunner{handler: func(ctx context.Context, command string) ([]byte, error) {
+		if strings.HasSuffix(command, "config -q") {
+			return []byte("services.app.restart contains an invalid type, it should be one of no, always, on-failure, unless-stopped\n"), errors.New("exit status 15")
+		}
+		return nil, nil
+	}}
+
+	err := provider.ValidateComposeFile(context.Background(), config)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "services.app.restart contains an invalid type")
+}
+
+func TestValidateComposeFileCommandFailure(t *testing.T) {
+	provider := NewDockerComposeProvider()
+	provider.runner = &fakeRunner{handler: func(ctx context.Context, command string) ([]byte, error) {
+		return nil, errors.New("executable file not found in $PATH")
+	}}
+
+	err := provider.ValidateComposeFile(context.Background(), testConfig())
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "executable file not found")
+}
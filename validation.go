@@ -0,0 +1,536 @@
+package thirdpartyhosting
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// defaultProtocol is used for port mappings that don't specify a protocol
+const defaultProtocol = "tcp"
+
+// supportedProtocols lists the transport protocols Docker can publish ports for
+var supportedProtocols = map[string]bool{
+	"tcp":  true,
+	"udp":  true,
+	"sctp": true,
+}
+
+// cgroupModes lists the cgroup namespace modes compose accepts
+var cgroupModes = map[string]bool{
+	"host":    true,
+	"private": true,
+}
+
+// isolationModes lists the Windows container isolation technologies compose accepts
+var isolationModes = map[string]bool{
+	"process": true,
+	"hyperv":  true,
+	"default": true,
+}
+
+// externalLinkPattern matches "container" or "container:alias" using Docker's container name characters
+var externalLinkPattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*(:[a-zA-Z0-9][a-zA-Z0-9_.-]*)?$`)
+
+// dnsOptPattern matches a resolver option, "key" or "key:value", e.g. "rotate" or "ndots:2"
+var dnsOptPattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]*(:[^\s:]+)?$`)
+
+// platformPattern matches an "os/arch" or "os/arch/variant" platform, e.g. "linux/arm64/v8"
+var platformPattern = regexp.MustCompile(`^[a-z0-9]+/[a-z0-9_]+(/[a-z0-9]+)?$`)
+
+// watchActions lists the develop.watch actions compose accepts
+var watchActions = map[string]bool{
+	"sync":         true,
+	"rebuild":      true,
+	"sync+restart": true,
+}
+
+// restartConditions lists the deploy.restart_policy conditions compose accepts
+var restartConditions = map[string]bool{
+	"none":       true,
+	"on-failure": true,
+	"any":        true,
+}
+
+// pullPolicies lists the pull_policy values compose accepts
+var pullPolicies = map[string]bool{
+	"always":  true,
+	"never":   true,
+	"missing": true,
+	"build":   true,
+}
+
+// ValidateConfig checks a ComposeConfig for values docker-compose would reject
+func ValidateConfig(config ComposeConfig) error {
+	return validateConfig(config, false)
+}
+
+// validateConfig checks config. A fragment, such as one compose file that is merged with
+// others before use, skips the checks that need the whole project: resolving extends,
+// requiring an image or build, and references to other services and to configs.
+func validateConfig(config ComposeConfig, fragment bool) error {
+	if !fragment {
+		var err error
+		if config, err = resolveExtends(config); err != nil {
+			return err
+		}
+	}
+
+	if config.RenderOptions.Indent < 0 {
+		return fmt.Errorf("render indent must not be negative, got %d", config.RenderOptions.Indent)
+	}
+	if style := config.RenderOptions.QuoteStyle; style != "" && style != "double" && style != "single" {
+		return fmt.Errorf("unsupported render quote style %q, must be double or single", style)
+	}
+
+	for name, configFile := range config.Configs {
+		if (configFile.File == "") == (configFile.Content == "") {
+			return fmt.Errorf("config %s: exactly one of file or content is required", name)
+		}
+	}
+
+	for _, labels := range []map[string]string{config.NetworkLabels, config.VolumeLabels} {
+		for key := range labels {
+			if strings.TrimSpace(key) == "" {
+				return fmt.Errorf("label keys must not be blank")
+			}
+		}
+	}
+
+	if config.Platform != "" && !platformPattern.MatchString(config.Platform) {
+		return fmt.Errorf("invalid platform %q, expected \"os/arch\" such as linux/amd64", config.Platform)
+	}
+
+	for serviceName, serviceConfig := range config.Services {
+		if !fragment && serviceConfig.ImageName == "" && serviceConfig.Build == nil {
+			return fmt.Errorf("service %s: an image or a build is required", serviceName)
+		}
+
+		if serviceConfig.Build != nil {
+			if err := validateBuild(*serviceConfig.Build); err != nil {
+				return fmt.Errorf("service %s: %w", serviceName, err)
+			}
+		}
+
+		for _, port := range serviceConfig.ExposedPorts {
+			if err := validatePortMapping(port); err != nil {
+				return fmt.Errorf("service %s: %w", serviceName, err)
+			}
+		}
+
+		for _, volume := range serviceConfig.Volumes {
+			if err := validateVolume(volume); err != nil {
+				return fmt.Errorf("service %s: %w", serviceName, err)
+			}
+			if config.StrictVolumes && volumeMappingType(volume) == "bind" {
+				if err := checkHostPathExists(config.BaseDir, volume.HostPath); err != nil {
+					return fmt.Errorf("service %s: %w", serviceName, err)
+				}
+			}
+		}
+
+		if serviceConfig.Develop != nil {
+			for _, rule := range serviceConfig.Develop.Watch {
+				if err := validateWatchRule(rule); err != nil {
+					return fmt.Errorf("service %s: %w", serviceName, err)
+				}
+			}
+		}
+
+		if err := validateResources(serviceConfig.Resources); err != nil {
+			return fmt.Errorf("service %s: %w", serviceName, err)
+		}
+
+		if serviceConfig.BlkioConfig != nil {
+			if err := validateBlkio(*serviceConfig.BlkioConfig); err != nil {
+				return fmt.Errorf("service %s: %w", serviceName, err)
+			}
+		}
+
+		if serviceConfig.EntrypointShell != "" && serviceConfig.Entrypoint != nil {
+			return fmt.Errorf("service %s: EntrypointShell and Entrypoint are mutually exclusive", serviceName)
+		}
+		if serviceConfig.CommandShell != "" && serviceConfig.Command != nil {
+			return fmt.Errorf("service %s: CommandShell and Command are mutually exclusive", serviceName)
+		}
+
+		if serviceConfig.PullPolicy != "" && !pullPolicies[serviceConfig.PullPolicy] {
+			return fmt.Errorf("service %s: unsupported pull_policy %q, must be one of always, never, missing, build", serviceName, serviceConfig.PullPolicy)
+		}
+
+		if err := validatePlatform(config.Platform, serviceConfig.Platform); err != nil {
+			return fmt.Errorf("service %s: %w", serviceName, err)
+		}
+
+		if serviceConfig.HealthCheck != nil {
+			if err := validateHealthCheck(*serviceConfig.HealthCheck); err != nil {
+				return fmt.Errorf("service %s: %w", serviceName, err)
+			}
+		}
+
+		if err := validateRestartPolicies(serviceConfig); err != nil {
+			return fmt.Errorf("service %s: %w", serviceName, err)
+		}
+		if d := serviceConfig.Deploy; d != nil && d.Replicas != nil && *d.Replicas < 0 {
+			return fmt.Errorf("service %s: deploy.replicas must not be negative, got %d", serviceName, *d.Replicas)
+		}
+
+		if serviceConfig.Cgroup != "" && !cgroupModes[serviceConfig.Cgroup] {
+			return fmt.Errorf("service %s: unsupported cgroup mode %q, must be host or private", serviceName, serviceConfig.Cgroup)
+		}
+
+		if serviceConfig.Runtime != "" && strings.TrimSpace(serviceConfig.Runtime) == "" {
+			return fmt.Errorf("service %s: runtime must not be blank", serviceName)
+		}
+
+		if serviceConfig.Isolation != "" && !isolationModes[serviceConfig.Isolation] {
+			return fmt.Errorf("service %s: unsupported isolation %q, must be one of process, hyperv, default", serviceName, serviceConfig.Isolation)
+		}
+
+		if serviceConfig.MacAddress != "" {
+			if _, err := net.ParseMAC(serviceConfig.MacAddress); err != nil {
+				return fmt.Errorf("service %s: invalid mac_address %q: %w", serviceName, serviceConfig.MacAddress, err)
+			}
+		}
+
+		for _, opt := range serviceConfig.DNSOpt {
+			if !dnsOptPattern.MatchString(opt) {
+				return fmt.Errorf("service %s: invalid dns_opt %q, expected \"key\" or \"key:value\"", serviceName, opt)
+			}
+		}
+
+		for _, group := range serviceConfig.GroupAdd {
+			if strings.TrimSpace(group) == "" {
+				return fmt.Errorf("service %s: group_add entries must not be blank", serviceName)
+			}
+		}
+
+		for _, dep := range serviceDependencies(serviceConfig) {
+			if _, exists := config.Services[dep]; !fragment && !exists {
+				return fmt.Errorf("service %s: depends on unknown service %s", serviceName, dep)
+			}
+		}
+
+		for dep, dependency := range serviceConfig.Dependencies {
+			if dependency.Condition != "" && !dependencyConditions[dependency.Condition] {
+				return fmt.Errorf("service %s: unsupported depends_on condition %q for %s, must be one of service_started, service_healthy, service_completed_successfully", serviceName, dependency.Condition, dep)
+			}
+		}
+
+		for _, link := range serviceConfig.Links {
+			target := strings.SplitN(link, ":", 2)[0]
+			if _, exists := config.Services[target]; !fragment && !exists {
+				return fmt.Errorf("service %s: link %q targets unknown service %s", serviceName, link, target)
+			}
+		}
+
+		for _, source := range serviceConfig.VolumesFrom {
+			if err := validateVolumesFrom(config, source, fragment); err != nil {
+				return fmt.Errorf("service %s: %w", serviceName, err)
+			}
+		}
+
+		for _, mount := range serviceConfig.Configs {
+			if _, exists := config.Configs[mount.Source]; !fragment && !exists {
+				return fmt.Errorf("service %s: config %s is not defined", serviceName, mount.Source)
+			}
+		}
+
+		for _, link := range serviceConfig.ExternalLinks {
+			if !externalLinkPattern.MatchString(link) {
+				return fmt.Errorf("service %s: invalid external link %q, expected \"container\" or \"container:alias\"", serviceName, link)
+			}
+		}
+
+		for _, opt := range serviceConfig.SecurityOpt {
+			if path := seccompProfilePath(opt); path != "" {
+				if _, err := os.Stat(resolveRelativePath(config.BaseDir, path)); err != nil {
+					return fmt.Errorf("service %s: seccomp profile %s: %w", serviceName, path, err)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// ConfigWarnings returns advisories for settings that are valid but likely to cause
+// problems at runtime. Unlike ValidateConfig it never rejects a configuration.
+func ConfigWarnings(config ComposeConfig) []string {
+	names := make([]string, 0, len(config.Services))
+	for name := range config.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var warnings []string
+	for _, serviceName := range names {
+		serviceConfig := config.Services[serviceName]
+		if serviceConfig.ReadOnlyRootFS && len(serviceConfig.Volumes) == 0 {
+			warnings = append(warnings, fmt.Sprintf("service %s: read_only root filesystem has no tmpfs or volume mounts, so the service cannot write anywhere", serviceName))
+		}
+		if isServiceActive(config, serviceConfig) {
+			for _, dep := range serviceDependencies(serviceConfig) {
+				if depConfig, exists := config.Services[dep]; exists && !isServiceActive(config, depConfig) {
+					warnings = append(warnings, fmt.Sprintf("service %s: depends on %s, which is not started because its profiles are not active", serviceName, dep))
+				}
+			}
+		}
+		if serviceConfig.DisableHealthCheck && serviceConfig.HealthCheck != nil {
+			warnings = append(warnings, fmt.Sprintf("service %s: healthcheck is ignored because DisableHealthCheck is set", serviceName))
+		}
+		if len(serviceConfig.Links) > 0 {
+			warnings = append(warnings, fmt.Sprintf("service %s: links is deprecated, services on a shared network can already reach each other by name", serviceName))
+		}
+		if len(serviceConfig.VolumesFrom) > 0 {
+			warnings = append(warnings, fmt.Sprintf("service %s: volumes_from is deprecated, mount the same named volumes in each service instead", serviceName))
+		}
+	}
+
+	return warnings
+}
+
+// validateVolumesFrom checks a volumes_from entry, "service[:ro|:rw]" or
+// "container:name[:ro|:rw]", and, unless config is a fragment, that a referenced service exists
+func validateVolumesFrom(config ComposeConfig, source string, fragment bool) error {
+	parts := strings.Split(source, ":")
+	external := parts[0] == "container"
+	if external {
+		parts = parts[1:]
+	}
+
+	if len(parts) == 0 || len(parts) > 2 || parts[0] == "" {
+		return fmt.Errorf("invalid volumes_from %q, expected \"service[:ro]\" or \"container:name[:ro]\"", source)
+	}
+	if len(parts) == 2 && parts[1] != "ro" && parts[1] != "rw" {
+		return fmt.Errorf("invalid volumes_from %q, access mode must be ro or rw", source)
+	}
+	if _, exists := config.Services[parts[0]]; !fragment && !external && !exists {
+		return fmt.Errorf("volumes_from %q references unknown service %s", source, parts[0])
+	}
+
+	return nil
+}
+
+// validatePortMapping checks a single port mapping
+func validatePortMapping(port PortMapping) error {
+	if port.Protocol != "" && !supportedProtocols[strings.ToLower(port.Protocol)] {
+		return fmt.Errorf("unsupported protocol %q for port %d, must be one of tcp, udp, sctp", port.Protocol, port.ContainerPort)
+	}
+
+	return nil
+}
+
+// checkHostPathExists checks that a bind mount's host path, resolved against baseDir, exists
+func checkHostPathExists(baseDir, hostPath string) error {
+	path := resolveRelativePath(baseDir, hostPath)
+	if strings.HasPrefix(path, "~") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return fmt.Errorf("volume %s: cannot resolve home directory: %w", hostPath, err)
+		}
+		path = filepath.Join(home, strings.TrimPrefix(path, "~"))
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("volume host path %s does not exist", path)
+		}
+		return fmt.Errorf("volume host path %s: %w", path, err)
+	}
+	return nil
+}
+
+// validateWatchRule checks a single develop.watch rule
+func validateWatchRule(rule WatchRule) error {
+	if rule.Path == "" {
+		return fmt.Errorf("watch rule path is required")
+	}
+	if !watchActions[rule.Action] {
+		return fmt.Errorf("watch rule %s: unsupported action %q, must be one of sync, rebuild, sync+restart", rule.Path, rule.Action)
+	}
+	if strings.HasPrefix(rule.Action, "sync") && rule.Target == "" {
+		return fmt.Errorf("watch rule %s: target is required for %s", rule.Path, rule.Action)
+	}
+	return nil
+}
+
+// validateBuild checks a service's build instructions
+func validateBuild(build BuildConfig) error {
+	if strings.TrimSpace(build.Context) == "" {
+		return fmt.Errorf("build context is required")
+	}
+	if build.Target != "" && strings.TrimSpace(build.Target) == "" {
+		return fmt.Errorf("build target must not be blank")
+	}
+	for _, image := range build.CacheFrom {
+		if strings.TrimSpace(image) == "" {
+			return fmt.Errorf("build cache_from entries must not be empty")
+		}
+	}
+
+	return nil
+}
+
+// validateVolume checks that a volume's options suit its type
+func validateVolume(volume VolumeMapping) error {
+	if volume.ContainerPath == "" {
+		return fmt.Errorf("volume %s: container path is required", volume.HostPath)
+	}
+
+	switch volumeMappingType(volume) {
+	case "bind":
+		if volume.NoCopy {
+			return fmt.Errorf("volume %s: nocopy only applies to named volumes", volume.ContainerPath)
+		}
+	case "volume":
+		if volume.Propagation != "" {
+			return fmt.Errorf("volume %s: propagation only applies to bind mounts", volume.ContainerPath)
+		}
+	case "tmpfs":
+		if volume.HostPath != "" {
+			return fmt.Errorf("volume %s: tmpfs mounts have no host path", volume.ContainerPath)
+		}
+	default:
+		return fmt.Errorf("volume %s: unsupported type %q, must be one of bind, volume, tmpfs", volume.ContainerPath, volume.Type)
+	}
+
+	return nil
+}
+
+// validatePlatform checks a service platform and that it targets the same OS as the
+// default platform, since one stack can't mix e.g. linux and windows containers
+func validatePlatform(defaultPlatform, platform string) error {
+	if platform == "" {
+		return nil
+	}
+	if !platformPattern.MatchString(platform) {
+		return fmt.Errorf("invalid platform %q, expected \"os/arch\" such as linux/amd64", platform)
+	}
+
+	if defaultPlatform != "" {
+		defaultOS := strings.SplitN(defaultPlatform, "/", 2)[0]
+		if serviceOS := strings.SplitN(platform, "/", 2)[0]; serviceOS != defaultOS {
+			return fmt.Errorf("platform %q targets %s but the default platform %q targets %s", platform, serviceOS, defaultPlatform, defaultOS)
+		}
+	}
+
+	return nil
+}
+
+// validateHealthCheck checks that a healthcheck's test is well formed for its form
+func validateHealthCheck(hc HealthCheck) error {
+	if hc.Shell != "" && len(hc.Test) > 0 {
+		return fmt.Errorf("healthcheck Shell and Test are mutually exclusive")
+	}
+	if len(hc.Test) == 0 {
+		return nil
+	}
+
+	switch hc.Test[0] {
+	case "NONE":
+		if len(hc.Test) != 1 {
+			return fmt.Errorf("healthcheck test NONE takes no arguments")
+		}
+	case "CMD":
+		if len(hc.Test) < 2 {
+			return fmt.Errorf("healthcheck test CMD needs a command")
+		}
+	case "CMD-SHELL":
+		if len(hc.Test) != 2 {
+			return fmt.Errorf("healthcheck test CMD-SHELL takes a single command string, use Shell instead")
+		}
+	}
+	return nil
+}
+
+// validateRestartPolicies checks deploy.restart_policy and rejects services that also set restart.
+// Outside swarm mode docker-compose applies restart and ignores deploy.restart_policy, so
+// setting both silently drops one of them.
+func validateRestartPolicies(service ServiceConfig) error {
+	if service.Deploy == nil || service.Deploy.RestartPolicy == nil {
+		return nil
+	}
+
+	policy := service.Deploy.RestartPolicy
+	if policy.Condition != "" && !restartConditions[policy.Condition] {
+		return fmt.Errorf("unsupported restart_policy condition %q, must be one of none, on-failure, any", policy.Condition)
+	}
+	if policy.MaxAttempts < 0 || policy.Delay < 0 || policy.Window < 0 {
+		return fmt.Errorf("restart_policy values must not be negative")
+	}
+	if service.RestartPolicy != "" {
+		return fmt.Errorf("restart %q conflicts with deploy.restart_policy; outside swarm mode restart wins and deploy.restart_policy is ignored, so set only one", service.RestartPolicy)
+	}
+
+	return nil
+}
+
+// validateResources checks resource limits that have a restricted range
+func validateResources(resources ResourceLimits) error {
+	if s := resources.MemSwappiness; s != nil && (*s < 0 || *s > 100) {
+		return fmt.Errorf("mem_swappiness must be between 0 and 100, got %d", *s)
+	}
+
+	if s := resources.OOMScoreAdj; s != nil && (*s < -1000 || *s > 1000) {
+		return fmt.Errorf("oom_score_adj must be between -1000 and 1000, got %d", *s)
+	}
+
+	if resources.CPUSet != "" {
+		if err := validateCPUSet(resources.CPUSet); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateBlkio checks the blkio weight range and that every device limit is complete
+func validateBlkio(blkio BlkioConfig) error {
+	if blkio.Weight != 0 && (blkio.Weight < 10 || blkio.Weight > 1000) {
+		return fmt.Errorf("blkio_config weight must be between 10 and 1000, got %d", blkio.Weight)
+	}
+
+	for _, rates := range [][]BlkioDeviceRate{blkio.DeviceReadBps, blkio.DeviceWriteBps, blkio.DeviceReadIOps, blkio.DeviceWriteIOps} {
+		for _, rate := range rates {
+			if rate.Path == "" || rate.Rate == "" {
+				return fmt.Errorf("blkio_config device limits require a path and a rate")
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateCPUSet checks a cpuset list such as "0-3" or "0,2,4-5"
+func validateCPUSet(cpuset string) error {
+	for _, part := range strings.Split(cpuset, ",") {
+		bounds := strings.SplitN(part, "-", 2)
+		if !isDigits(bounds[0]) || (len(bounds) == 2 && !isDigits(bounds[1])) {
+			return fmt.Errorf("invalid cpuset %q, expected a list of CPUs or ranges such as \"0-3\" or \"0,2\"", cpuset)
+		}
+
+		if len(bounds) == 2 {
+			start, _ := strconv.Atoi(bounds[0])
+			end, _ := strconv.Atoi(bounds[1])
+			if start > end {
+				return fmt.Errorf("invalid cpuset %q, range %s is reversed", cpuset, part)
+			}
+		}
+	}
+
+	return nil
+}
+
+// portProtocol returns the protocol for a port mapping, defaulting to tcp
+func portProtocol(port PortMapping) string {
+	if port.Protocol == "" {
+		return defaultProtocol
+	}
+	return strings.ToLower(port.Protocol)
+}
@@ -0,0 +1,545 @@
+package thirdpartyhosting
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateConfigProtocols(t *testing.T) {
+	for _, protocol := range []string{"", "tcp", "udp", "sctp", "SCTP"} {
+		config := ComposeConfig{
+			Services: map[string]ServiceConfig{
+				"app": {
+					ImageName:    "app",
+					ExposedPorts: []PortMapping{{HostPort: 3868, ContainerPort: 3868, Protocol: protocol}},
+				},
+			},
+		}
+		assert.NoError(t, ValidateConfig(config), "protocol %q", protocol)
+	}
+
+	config := ComposeConfig{
+		Services: map[string]ServiceConfig{
+			"app": {
+				ImageName:    "app",
+				ExposedPorts: []PortMapping{{HostPort: 80, ContainerPort: 80, Protocol: "icmp"}},
+			},
+		},
+	}
+	err := ValidateConfig(config)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "icmp")
+}
+
+func TestValidateConfigMemSwappiness(t *testing.T) {
+	for value, valid := range map[int]bool{0: true, 60: true, 100: true, -1: false, 101: false} {
+		swappiness := value
+		config := ComposeConfig{
+			Services: map[string]ServiceConfig{
+				"app": {ImageName: "app", Resources: ResourceLimits{MemSwappiness: &swappiness}},
+			},
+		}
+
+		err := ValidateConfig(config)
+		if valid {
+			assert.NoError(t, err, "swappiness %d", value)
+		} else {
+			assert.Error(t, err, "swappiness %d", value)
+		}
+	}
+}
+
+func TestValidateConfigOOMScoreAdj(t *testing.T) {
+	for value, valid := range map[int]bool{-1000: true, 0: true, 1000: true, -1001: false, 1001: false} {
+		scoreAdj := value
+		config := ComposeConfig{
+			Services: map[string]ServiceConfig{
+				"app": {ImageName: "app", Resources: ResourceLimits{OOMScoreAdj: &scoreAdj}},
+			},
+		}
+
+		err := ValidateConfig(config)
+		if valid {
+			assert.NoError(t, err, "oom_score_adj %d", value)
+		} else {
+			assert.Error(t, err, "oom_score_adj %d", value)
+		}
+	}
+}
+
+func TestValidateConfigCPUSet(t *testing.T) {
+	for cpuset, valid := range map[string]bool{
+		"0":       true,
+		"0-3":     true,
+		"0,2":     true,
+		"0-1,4-5": true,
+		"3-1":     false,
+		"0-":      false,
+		"a,b":     false,
+		"0,,1":    false,
+	} {
+		config := ComposeConfig{
+			Services: map[string]ServiceConfig{
+				"app": {ImageName: "app", Resources: ResourceLimits{CPUSet: cpuset}},
+			},
+		}
+
+		err := ValidateConfig(config)
+		if valid {
+			assert.NoError(t, err, "cpuset %q", cpuset)
+		} else {
+			assert.Error(t, err, "cpuset %q", cpuset)
+		}
+	}
+}
+
+func TestValidateConfigBuild(t *testing.T) {
+	valid := ComposeConfig{
+		Services: map[string]ServiceConfig{
+			"api": {Build: &BuildConfig{Context: ".", Target: "production"}},
+		},
+	}
+	assert.NoError(t, ValidateConfig(valid))
+
+	blankTarget := ComposeConfig{
+		Services: map[string]ServiceConfig{
+			"api": {Build: &BuildConfig{Context: ".", Target: "  "}},
+		},
+	}
+	assert.Error(t, ValidateConfig(blankTarget))
+
+	missingContext := ComposeConfig{
+		Services: map[string]ServiceConfig{
+			"api": {Build: &BuildConfig{Target: "production"}},
+		},
+	}
+	assert.Error(t, ValidateConfig(missingContext))
+
+	noImage := ComposeConfig{
+		Services: map[string]ServiceConfig{
+			"api": {},
+		},
+	}
+	assert.Error(t, ValidateConfig(noImage))
+}
+
+func TestValidateConfigVolumes(t *testing.T) {
+	for _, tc := range []struct {
+		volume VolumeMapping
+		valid  bool
+	}{
+		{VolumeMapping{HostPath: "/data", ContainerPath: "/data", Propagation: "rshared"}, true},
+		{VolumeMapping{HostPath: "data", ContainerPath: "/data", NoCopy: true}, true},
+		{VolumeMapping{Type: "tmpfs", ContainerPath: "/tmp"}, true},
+		{VolumeMapping{HostPath: "/data", ContainerPath: "/data", NoCopy: true}, false},
+		{VolumeMapping{HostPath: "data", ContainerPath: "/data", Propagation: "rshared"}, false},
+		{VolumeMapping{Type: "tmpfs", HostPath: "/tmp", ContainerPath: "/tmp"}, false},
+		{VolumeMapping{Type: "npipe", HostPath: "/x", ContainerPath: "/x"}, false},
+		{VolumeMapping{HostPath: "/data"}, false},
+	} {
+		config := ComposeConfig{
+			Services: map[string]ServiceConfig{
+				"app": {ImageName: "app", Volumes: []VolumeMapping{tc.volume}},
+			},
+		}
+
+		err := ValidateConfig(config)
+		if tc.valid {
+			assert.NoError(t, err, "%+v", tc.volume)
+		} else {
+			assert.Error(t, err, "%+v", tc.volume)
+		}
+	}
+}
+
+func TestValidateConfigCgroup(t *testing.T) {
+	for mode, valid := range map[string]bool{"": true, "host": true, "private": true, "shared": false} {
+		config := ComposeConfig{
+			Services: map[string]ServiceConfig{
+				"app": {ImageName: "app", Cgroup: mode},
+			},
+		}
+
+		err := ValidateConfig(config)
+		if valid {
+			assert.NoError(t, err, "cgroup %q", mode)
+		} else {
+			assert.Error(t, err, "cgroup %q", mode)
+		}
+	}
+}
+
+func TestValidateConfigRestartPolicyConflict(t *testing.T) {
+	service := ServiceConfig{
+		ImageName:     "app",
+		RestartPolicy: "always",
+		Deploy: &DeployConfig{
+			RestartPolicy: &DeployRestartPolicy{Condition: "on-failure", MaxAttempts: 3},
+		},
+	}
+	config := ComposeConfig{Services: map[string]ServiceConfig{"app": service}}
+
+	err := ValidateConfig(config)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "conflicts with deploy.restart_policy")
+
+	service.RestartPolicy = ""
+	config.Services["app"] = service
+	assert.NoError(t, ValidateConfig(config))
+
+	service.Deploy.RestartPolicy.Condition = "sometimes"
+	config.Services["app"] = service
+	assert.Error(t, ValidateConfig(config))
+}
+
+func TestValidateConfigMacAddress(t *testing.T) {
+	valid := ComposeConfig{
+		Services: map[string]ServiceConfig{
+			"app": {ImageName: "app", MacAddress: "02:42:ac:11:00:02"},
+		},
+	}
+	assert.NoError(t, ValidateConfig(valid))
+
+	invalid := ComposeConfig{
+		Services: map[string]ServiceConfig{
+			"app": {ImageName: "app", MacAddress: "02:42:zz:11"},
+		},
+	}
+	err := ValidateConfig(invalid)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "mac_address")
+}
+
+func TestConfigWarningsReadOnlyRootFS(t *testing.T) {
+	config := ComposeConfig{
+		Services: map[string]ServiceConfig{
+			"bare":    {ImageName: "bare", ReadOnlyRootFS: true},
+			"mounted": {ImageName: "mounted", ReadOnlyRootFS: true, Volumes: []VolumeMapping{{Type: "tmpfs", ContainerPath: "/tmp"}}},
+			"regular": {ImageName: "regular"},
+		},
+	}
+
+	warnings := ConfigWarnings(config)
+
+	assert.NoError(t, ValidateConfig(config))
+	assert.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "service bare")
+}
+
+func TestValidateConfigLinks(t *testing.T) {
+	config := ComposeConfig{
+		Services: map[string]ServiceConfig{
+			"app": {ImageName: "app", Links: []string{"db:database"}},
+			"db":  {ImageName: "postgres"},
+		},
+	}
+	assert.NoError(t, ValidateConfig(config))
+
+	warnings := ConfigWarnings(config)
+	assert.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "links is deprecated")
+
+	config.Services["app"] = ServiceConfig{ImageName: "app", Links: []string{"cache:redis"}}
+	err := ValidateConfig(config)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown service cache")
+}
+
+func TestValidateConfigBlankRuntime(t *testing.T) {
+	config := ComposeConfig{
+		Services: map[string]ServiceConfig{
+			"app": {ImageName: "app", Runtime: "  "},
+		},
+	}
+
+	err := ValidateConfig(config)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "runtime")
+}
+
+func TestValidateConfigDependencyInInactiveProfile(t *testing.T) {
+	config := ComposeConfig{
+		Services: map[string]ServiceConfig{
+			"app":    {ImageName: "app", DependsOn: []string{"db", "mailer"}},
+			"db":     {ImageName: "postgres"},
+			"mailer": {ImageName: "mailhog", Profiles: []string{"dev"}},
+		},
+	}
+
+	assert.NoError(t, ValidateConfig(config))
+	warnings := ConfigWarnings(config)
+	assert.Equal(t, []string{"service app: depends on mailer, which is not started because its profiles are not active"}, warnings)
+
+	config.ActiveProfiles = []string{"dev"}
+	assert.Empty(t, ConfigWarnings(config))
+}
+
+func TestValidateConfigUnknownDependency(t *testing.T) {
+	config := ComposeConfig{
+		Services: map[string]ServiceConfig{
+			"app": {ImageName: "app", DependsOn: []string{"cache"}},
+		},
+	}
+
+	err := ValidateConfig(config)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown service cache")
+}
+
+func TestValidateConfigIsolation(t *testing.T) {
+	for isolation, valid := range map[string]bool{"process": true, "hyperv": true, "default": true, "vm": false} {
+		config := ComposeConfig{
+			Services: map[string]ServiceConfig{
+				"app": {ImageName: "app", Isolation: isolation},
+			},
+		}
+
+		err := ValidateConfig(config)
+		if valid {
+			assert.NoError(t, err, "isolation %s", isolation)
+		} else {
+			assert.Error(t, err, "isolation %s", isolation)
+		}
+	}
+}
+
+func TestValidateConfigExternalLinks(t *testing.T) {
+	for link, valid := range map[string]bool{
+		"shared-postgres":    true,
+		"shared-postgres:db": true,
+		"infra_redis.1:c":    true,
+		":db":                false,
+		"postgres:":          false,
+		"a:b:c":              false,
+		"has space":          false,
+	} {
+		config := ComposeConfig{
+			Services: map[string]ServiceConfig{
+				"app": {ImageName: "app", ExternalLinks: []string{link}},
+			},
+		}
+
+		err := ValidateConfig(config)
+		if valid {
+			assert.NoError(t, err, "external link %q", link)
+		} else {
+			assert.Error(t, err, "external link %q", link)
+		}
+	}
+}
+
+func TestValidateConfigStrictVolumes(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "data"), 0755))
+
+	config := ComposeConfig{
+		BaseDir:       dir,
+		StrictVolumes: true,
+		Services: map[string]ServiceConfig{
+			"app": {
+				ImageName: "app",
+				Volumes: []VolumeMapping{
+					{HostPath: "./data", ContainerPath: "/data"},
+					{HostPath: filepath.Join(dir, "data"), ContainerPath: "/abs"},
+					{HostPath: "app_cache", ContainerPath: "/cache"},
+					{Type: "tmpfs", ContainerPath: "/tmp"},
+				},
+			},
+		},
+	}
+	assert.NoError(t, ValidateConfig(config))
+
+	config.Services["app"] = ServiceConfig{
+		ImageName: "app",
+		Volumes:   []VolumeMapping{{HostPath: "./missing", ContainerPath: "/data"}},
+	}
+	err := ValidateConfig(config)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "service app")
+	assert.Contains(t, err.Error(), filepath.Join(dir, "missing")+" does not exist")
+
+	config.StrictVolumes = false
+	assert.NoError(t, ValidateConfig(config))
+}
+
+func TestValidateConfigBlkioWeight(t *testing.T) {
+	for weight, valid := range map[int]bool{0: true, 10: true, 1000: true, 9: false, 1001: false} {
+		config := ComposeConfig{
+			Services: map[string]ServiceConfig{
+				"db": {ImageName: "postgres", BlkioConfig: &BlkioConfig{Weight: weight}},
+			},
+		}
+
+		err := ValidateConfig(config)
+		if valid {
+			assert.NoError(t, err, "weight %d", weight)
+		} else {
+			assert.Error(t, err, "weight %d", weight)
+		}
+	}
+}
+
+func TestValidateConfigBlkioDeviceRequiresPathAndRate(t *testing.T) {
+	config := ComposeConfig{
+		Services: map[string]ServiceConfig{
+			"db": {ImageName: "postgres", BlkioConfig: &BlkioConfig{
+				DeviceReadIOps: []BlkioDeviceRate{{Path: "/dev/sdb"}},
+			}},
+		},
+	}
+
+	assert.Error(t, ValidateConfig(config))
+}
+
+func TestValidateConfigRejectsBlankGroupAdd(t *testing.T) {
+	config := ComposeConfig{
+		Services: map[string]ServiceConfig{
+			"ci": {ImageName: "docker", GroupAdd: []string{"docker", " "}},
+		},
+	}
+
+	assert.Error(t, ValidateConfig(config))
+}
+
+func TestValidateConfigDNSOpt(t *testing.T) {
+	for opt, valid := range map[string]bool{
+		"ndots:2":   true,
+		"timeout:3": true,
+		"rotate":    true,
+		"":          false,
+		"ndots 2":   false,
+		":2":        false,
+		"ndots:":    false,
+	} {
+		config := ComposeConfig{
+			Services: map[string]ServiceConfig{
+				"app": {ImageName: "app", DNSOpt: []string{opt}},
+			},
+		}
+
+		err := ValidateConfig(config)
+		if valid {
+			assert.NoError(t, err, "dns_opt %q", opt)
+		} else {
+			assert.Error(t, err, "dns_opt %q", opt)
+		}
+	}
+}
+
+func TestValidateConfigPlatform(t *testing.T) {
+	tests := []struct {
+		defaultPlatform, platform string
+		valid                     bool
+	}{
+		{"", "linux/arm64", true},
+		{"linux/amd64", "linux/arm64/v8", true},
+		{"linux/amd64", "", true},
+		{"", "arm64", false},
+		{"linux", "", false},
+		{"linux/amd64", "windows/amd64", false},
+	}
+
+	for _, tt := range tests {
+		config := ComposeConfig{
+			Platform: tt.defaultPlatform,
+			Services: map[string]ServiceConfig{
+				"app": {ImageName: "app", Platform: tt.platform},
+			},
+		}
+
+		err := ValidateConfig(config)
+		if tt.valid {
+			assert.NoError(t, err, "%q / %q", tt.defaultPlatform, tt.platform)
+		} else {
+			assert.Error(t, err, "%q / %q", tt.defaultPlatform, tt.platform)
+		}
+	}
+}
+
+func TestValidateConfigVolumesFrom(t *testing.T) {
+	for source, valid := range map[string]bool{
+		"data":                  true,
+		"data:ro":               true,
+		"data:rw":               true,
+		"container:legacy":      true,
+		"container:legacy:ro":   true,
+		"missing":               false,
+		"data:readonly":         false,
+		"container:":            false,
+		"container:legacy:ro:x": false,
+	} {
+		config := ComposeConfig{
+			Services: map[string]ServiceConfig{
+				"data":   {ImageName: "busybox"},
+				"backup": {ImageName: "backup", VolumesFrom: []string{source}},
+			},
+		}
+
+		err := ValidateConfig(config)
+		if valid {
+			assert.NoError(t, err, "volumes_from %q", source)
+		} else {
+			assert.Error(t, err, "volumes_from %q", source)
+		}
+	}
+}
+
+func TestConfigWarningsVolumesFromDeprecated(t *testing.T) {
+	config := ComposeConfig{
+		Services: map[string]ServiceConfig{
+			"data":   {ImageName: "busybox"},
+			"backup": {ImageName: "backup", VolumesFrom: []string{"data:ro"}},
+		},
+	}
+
+	warnings := ConfigWarnings(config)
+
+	assert.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "service backup: volumes_from is deprecated")
+}
+
+func TestValidateConfigShellFormExclusive(t *testing.T) {
+	for _, service := range []ServiceConfig{
+		{ImageName: "app", CommandShell: "npm start", Command: []string{"npm", "start"}},
+		{ImageName: "app", EntrypointShell: "/entrypoint.sh", Entrypoint: []string{}},
+	} {
+		assert.Error(t, ValidateConfig(ComposeConfig{Services: map[string]ServiceConfig{"app": service}}))
+	}
+
+	assert.NoError(t, ValidateConfig(ComposeConfig{Services: map[string]ServiceConfig{
+		"app": {ImageName: "app", CommandShell: "npm start", Entrypoint: []string{"tini", "--"}},
+	}}))
+}
+
+func TestValidateConfigHealthCheckForms(t *testing.T) {
+	for _, hc := range []HealthCheck{
+		{Test: []string{"NONE", "true"}},
+		{Test: []string{"CMD"}},
+		{Test: []string{"CMD-SHELL", "curl", "-f", "localhost"}},
+		{Test: []string{"CMD", "true"}, Shell: "true"},
+	} {
+		healthCheck := hc
+		config := ComposeConfig{Services: map[string]ServiceConfig{"app": {ImageName: "app", HealthCheck: &healthCheck}}}
+		assert.Error(t, ValidateConfig(config), "%v", hc)
+	}
+
+	for _, hc := range []HealthCheck{
+		{Test: []string{"NONE"}},
+		{Test: []string{"CMD", "true"}},
+		{Test: []string{"CMD-SHELL", "curl -f localhost || exit 1"}},
+		{Test: []string{"curl", "-f", "localhost"}},
+		{Shell: "curl -f localhost || exit 1"},
+		{Interval: time.Second},
+	} {
+		healthCheck := hc
+		config := ComposeConfig{Services: map[string]ServiceConfig{"app": {ImageName: "app", HealthCheck: &healthCheck}}}
+		assert.NoError(t, ValidateConfig(config), "%v", hc)
+	}
+}
@@ -0,0 +1,90 @@
+package thirdpartyhosting
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// RenderWithValues renders config as a compose file after resolving text/template
+// placeholders such as {{ .db_host }} in environment values, commands and entrypoints
+// from values. This is separate from ${VAR} host environment expansion, which compose
+// performs itself. A placeholder naming a key missing from values is an error.
+func RenderWithValues(config ComposeConfig, values map[string]string) (string, error) {
+	services := make(map[string]ServiceConfig, len(config.Services))
+	for serviceName, serviceConfig := range config.Services {
+		resolved, err := applyValues(serviceConfig, values)
+		if err != nil {
+			return "", fmt.Errorf("service %s: %w", serviceName, err)
+		}
+		services[serviceName] = resolved
+	}
+	config.Services = services
+
+	return generateComposeContent(config)
+}
+
+// applyValues returns a copy of service with its templated fields resolved from values
+func applyValues(service ServiceConfig, values map[string]string) (ServiceConfig, error) {
+	if service.Environment != nil {
+		env := make(map[string]string, len(service.Environment))
+		for key, value := range service.Environment {
+			resolved, err := executeValueTemplate("environment "+key, value, values)
+			if err != nil {
+				return ServiceConfig{}, err
+			}
+			env[key] = resolved
+		}
+		service.Environment = env
+	}
+
+	var err error
+	if service.CommandShell, err = executeValueTemplate("command", service.CommandShell, values); err != nil {
+		return ServiceConfig{}, err
+	}
+	if service.EntrypointShell, err = executeValueTemplate("entrypoint", service.EntrypointShell, values); err != nil {
+		return ServiceConfig{}, err
+	}
+	if service.Command, err = applyValuesToList("command", service.Command, values); err != nil {
+		return ServiceConfig{}, err
+	}
+	if service.Entrypoint, err = applyValuesToList("entrypoint", service.Entrypoint, values); err != nil {
+		return ServiceConfig{}, err
+	}
+
+	return service, nil
+}
+
+// applyValuesToList resolves each entry of a list, keeping nil and empty lists as they are
+func applyValuesToList(name string, list []string, values map[string]string) ([]string, error) {
+	if list == nil {
+		return nil, nil
+	}
+
+	resolved := make([]string, len(list))
+	for i, entry := range list {
+		var err error
+		if resolved[i], err = executeValueTemplate(name, entry, values); err != nil {
+			return nil, err
+		}
+	}
+	return resolved, nil
+}
+
+// executeValueTemplate resolves a single templated string, failing on missing keys
+func executeValueTemplate(name, text string, values map[string]string) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid template in %s: %w", name, err)
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, values); err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", name, err)
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,41 @@
+package thirdpartyhosting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderWithValues(t *testing.T) {
+	config := ComposeConfig{
+		Services: map[string]ServiceConfig{
+			"app": {
+				ImageName:   "app",
+				Environment: map[string]string{"DATABASE_URL": "postgres://{{ .db_user }}@db/{{ .db_name }}", "HOME_DIR": "${HOME}"},
+				Command:     []string{"serve", "--replicas={{ .replicas }}"},
+			},
+		},
+	}
+
+	content, err := RenderWithValues(config, map[string]string{"db_user": "fider", "db_name": "fider_prod", "replicas": "3"})
+
+	assert.NoError(t, err)
+	assert.Contains(t, content, "      - DATABASE_URL=postgres://fider@db/fider_prod\n")
+	assert.Contains(t, content, "      - HOME_DIR=${HOME}\n")
+	assert.Contains(t, content, "    command: [\"serve\", \"--replicas=3\"]\n")
+	assert.Equal(t, "postgres://{{ .db_user }}@db/{{ .db_name }}", config.Services["app"].Environment["DATABASE_URL"])
+}
+
+func TestRenderWithValuesMissingKey(t *testing.T) {
+	config := ComposeConfig{
+		Services: map[string]ServiceConfig{
+			"app": {ImageName: "app", Environment: map[string]string{"API_KEY": "{{ .api_key }}"}},
+		},
+	}
+
+	_, err := RenderWithValues(config, map[string]string{})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "service app")
+	assert.Contains(t, err.Error(), "api_key")
+}
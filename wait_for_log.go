@@ -0,0 +1,81 @@
+package thirdpartyhosting
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// WaitForLog follows a service's logs from the beginning and returns once a line matches
+// pattern, which suits services that signal readiness with a line such as "server started".
+// It fails if timeout elapses or the log stream ends first. The follow process is killed on return.
+func (p *DockerComposeProvider) WaitForLog(ctx context.Context, serviceName string, pattern *regexp.Regexp, timeout time.Duration) error {
+	containerID, err := p.ResolveContainerID(ctx, serviceName)
+	if err != nil {
+		return err
+	}
+
+	followCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	matcher := &logMatcher{pattern: pattern, cancel: cancel}
+	err = p.commands().Stream(followCtx, nil, matcher, "docker", "logs", "--follow", containerID)
+	if matcher.Flush() {
+		return nil
+	}
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if followCtx.Err() != nil {
+		return fmt.Errorf("timed out after %v waiting for service %s to log a line matching %q", timeout, serviceName, pattern)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to follow logs for service %s: %w", serviceName, err)
+	}
+	return fmt.Errorf("logs for service %s ended without a line matching %q", serviceName, pattern)
+}
+
+// logMatcher scans written output line by line and cancels the follow once a line matches
+type logMatcher struct {
+	pattern *regexp.Regexp
+	cancel  context.CancelFunc
+	matched bool
+	partial []byte
+}
+
+// Write checks any complete lines p finishes
+func (m *logMatcher) Write(p []byte) (int, error) {
+	m.partial = append(m.partial, p...)
+
+	for !m.matched {
+		i := bytes.IndexByte(m.partial, '\n')
+		if i < 0 {
+			break
+		}
+		m.check(string(m.partial[:i]))
+		m.partial = m.partial[i+1:]
+	}
+
+	return len(p), nil
+}
+
+// Flush checks any trailing line that wasn't newline-terminated and reports whether a line matched
+func (m *logMatcher) Flush() bool {
+	if !m.matched && len(m.partial) > 0 {
+		m.check(string(m.partial))
+		m.partial = nil
+	}
+	return m.matched
+}
+
+// check matches a single line, stopping the follow on success
+func (m *logMatcher) check(line string) {
+	if m.pattern.MatchString(strings.TrimRight(line, "\r")) {
+		m.matched = true
+		m.cancel()
+	}
+}
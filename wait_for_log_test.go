@@ -0,0 +1,69 @@
+package thirdpartyhosting
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// followHandler answers ps for app and docker logs --follow through logs
+func followHandler(logs func(ctx context.Context) ([]byte, error)) func(ctx context.Context, command string) ([]byte, error) {
+	return func(ctx context.Context, command string) ([]byte, error) {
+		if strings.HasSuffix(command, "ps -q app") {
+			return []byte("abc123\n"), nil
+		}
+		if command == "docker logs --follow abc123" {
+			return logs(ctx)
+		}
+		return nil, nil
+	}
+}
+
+func TestWaitForLogMatchesThirdLine(t *testing.T) {
+	provider, runner := newTestProvider(t, testConfig(), followHandler(func(ctx context.Context) ([]byte, error) {
+		return []byte("loading config\nconnecting to db\nserver started on :3000\nGET /health 200\n"), nil
+	}))
+
+	err := provider.WaitForLog(context.Background(), "app", regexp.MustCompile(`server started`), time.Second)
+
+	assert.NoError(t, err)
+	assert.Len(t, runner.CallsContaining("docker logs --follow abc123"), 1)
+}
+
+func TestWaitForLogTimeout(t *testing.T) {
+	provider, _ := newTestProvider(t, testConfig(), followHandler(func(ctx context.Context) ([]byte, error) {
+		<-ctx.Done()
+		return []byte("still booting\n"), ctx.Err()
+	}))
+
+	err := provider.WaitForLog(context.Background(), "app", regexp.MustCompile(`server started`), 10*time.Millisecond)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "timed out")
+}
+
+func TestWaitForLogStreamEnds(t *testing.T) {
+	provider, _ := newTestProvider(t, testConfig(), followHandler(func(ctx context.Context) ([]byte, error) {
+		return []byte("panic: missing DATABASE_URL"), nil
+	}))
+
+	err := provider.WaitForLog(context.Background(), "app", regexp.MustCompile(`server started`), time.Second)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "ended without a line matching")
+}
+
+func TestLogMatcherPartialLines(t *testing.T) {
+	_, cancel := context.WithCancel(context.Background())
+	matcher := &logMatcher{pattern: regexp.MustCompile(`^ready$`), cancel: cancel}
+
+	matcher.Write([]byte("rea"))
+	assert.False(t, matcher.matched)
+	matcher.Write([]byte("dy\r\n"))
+
+	assert.True(t, matcher.Flush())
+}
@@ -0,0 +1,132 @@
+package thirdpartyhosting
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Watchdog event actions
+const (
+	WatchdogRestarted     = "restarted"      // the unhealthy service's container was restarted
+	WatchdogRestartFailed = "restart_failed" // docker restart failed; Err says why
+	WatchdogGaveUp        = "gave_up"        // MaxRestarts was reached, the service is left unhealthy
+)
+
+// WatchdogPolicy controls how StartWatchdog reacts to unhealthy services
+type WatchdogPolicy struct {
+	Interval    time.Duration // time between health checks; defaults to the provider's poll interval
+	MaxRestarts int           // restarts per service before giving up; 0 means no limit
+	Cooldown    time.Duration // minimum time between restarts of the same service
+}
+
+// WatchdogEvent reports an action the watchdog took for a service
+type WatchdogEvent struct {
+	Service  string
+	Action   string // WatchdogRestarted, WatchdogRestartFailed or WatchdogGaveUp
+	Restarts int    // restarts of this service so far
+	Err      error
+}
+
+// StartWatchdog polls service health in the background and restarts the containers of
+// services that report unhealthy, within the limits of policy. Each action is sent on the
+// returned channel, which must be drained; it is closed once ctx is cancelled.
+func (p *DockerComposeProvider) StartWatchdog(ctx context.Context, policy WatchdogPolicy) (<-chan WatchdogEvent, error) {
+	p.mu.RLock()
+	initialized := p.initialized
+	interval := p.pollInterval
+	p.mu.RUnlock()
+
+	if !initialized {
+		return nil, fmt.Errorf("provider not initialized")
+	}
+	if policy.Interval < 0 || policy.MaxRestarts < 0 || policy.Cooldown < 0 {
+		return nil, fmt.Errorf("watchdog policy values must not be negative")
+	}
+	if policy.Interval > 0 {
+		interval = policy.Interval
+	}
+
+	events := make(chan WatchdogEvent)
+	go func() {
+		defer close(events)
+
+		restarts := make(map[string]int)
+		lastRestart := make(map[string]time.Time)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(interval):
+			}
+
+			statuses, err := p.Status(ctx)
+			if err != nil {
+				continue // the daemon may be briefly unavailable; try again next round
+			}
+
+			var unhealthy []string
+			for service, status := range statuses {
+				if status == "unhealthy" {
+					unhealthy = append(unhealthy, service)
+				}
+			}
+			sort.Strings(unhealthy)
+
+			for _, service := range unhealthy {
+				if policy.MaxRestarts > 0 && restarts[service] >= policy.MaxRestarts {
+					if restarts[service] == policy.MaxRestarts {
+						restarts[service]++ // report giving up only once
+						if !sendWatchdogEvent(ctx, events, WatchdogEvent{Service: service, Action: WatchdogGaveUp, Restarts: policy.MaxRestarts}) {
+							return
+						}
+					}
+					continue
+				}
+				if last, ok := lastRestart[service]; ok && time.Since(last) < policy.Cooldown {
+					continue
+				}
+
+				event := WatchdogEvent{Service: service, Action: WatchdogRestarted}
+				if err := p.restartContainer(ctx, service); err != nil {
+					event.Action = WatchdogRestartFailed
+					event.Err = err
+				} else {
+					restarts[service]++
+				}
+				lastRestart[service] = time.Now()
+				event.Restarts = restarts[service]
+
+				if !sendWatchdogEvent(ctx, events, event) {
+					return
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// restartContainer restarts a service's current container with docker restart
+func (p *DockerComposeProvider) restartContainer(ctx context.Context, service string) error {
+	containerID := p.GetContainerID(service)
+	if containerID == "" {
+		return fmt.Errorf("container for service %s not found", service)
+	}
+
+	if output, err := p.commands().Run(ctx, "docker", "restart", containerID); err != nil {
+		return fmt.Errorf("failed to restart service %s: %s, error: %w", service, string(output), err)
+	}
+	return nil
+}
+
+// sendWatchdogEvent delivers event unless ctx is cancelled first
+func sendWatchdogEvent(ctx context.Context, events chan<- WatchdogEvent, event WatchdogEvent) bool {
+	select {
+	case events <- event:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,113 @@
+package thirdpartyhosting
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// nextWatchdogEvent waits for the next event, failing the test after a second
+func nextWatchdogEvent(t *testing.T, events <-chan WatchdogEvent) WatchdogEvent {
+	t.Helper()
+
+	select {
+	case event, ok := <-events:
+		require.True(t, ok, "watchdog channel closed")
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("no watchdog event")
+		return WatchdogEvent{}
+	}
+}
+
+func TestWatchdogRestartsUnhealthyService(t *testing.T) {
+	provider, runner := newTestProvider(t, testConfig(), healthHandler(map[string]string{"app": "unhealthy", "db": "healthy"}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := provider.StartWatchdog(ctx, WatchdogPolicy{Interval: time.Millisecond})
+	require.NoError(t, err)
+
+	event := nextWatchdogEvent(t, events)
+
+	assert.Equal(t, WatchdogEvent{Service: "app", Action: WatchdogRestarted, Restarts: 1}, event)
+	assert.Contains(t, runner.Calls(), "docker restart id-app")
+	assert.Empty(t, runner.CallsContaining("docker restart id-db"))
+}
+
+func TestWatchdogGivesUpAfterMaxRestarts(t *testing.T) {
+	provider, runner := newTestProvider(t, testConfig(), healthHandler(map[string]string{"app": "unhealthy", "db": "healthy"}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := provider.StartWatchdog(ctx, WatchdogPolicy{Interval: time.Millisecond, MaxRestarts: 2})
+	require.NoError(t, err)
+
+	assert.Equal(t, WatchdogRestarted, nextWatchdogEvent(t, events).Action)
+	assert.Equal(t, WatchdogRestarted, nextWatchdogEvent(t, events).Action)
+	assert.Equal(t, WatchdogEvent{Service: "app", Action: WatchdogGaveUp, Restarts: 2}, nextWatchdogEvent(t, events))
+
+	time.Sleep(20 * time.Millisecond)
+	assert.Len(t, runner.CallsContaining("docker restart"), 2)
+}
+
+func TestWatchdogCooldown(t *testing.T) {
+	provider, runner := newTestProvider(t, testConfig(), healthHandler(map[string]string{"app": "unhealthy", "db": "healthy"}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := provider.StartWatchdog(ctx, WatchdogPolicy{Interval: time.Millisecond, Cooldown: time.Hour})
+	require.NoError(t, err)
+
+	nextWatchdogEvent(t, events)
+	time.Sleep(20 * time.Millisecond)
+
+	assert.Len(t, runner.CallsContaining("docker restart"), 1)
+}
+
+func TestWatchdogReportsRestartFailure(t *testing.T) {
+	health := healthHandler(map[string]string{"app": "unhealthy", "db": "healthy"})
+	provider, _ := newTestProvider(t, testConfig(), func(ctx context.Context, command string) ([]byte, error) {
+		if strings.HasPrefix(command, "docker restart") {
+			return []byte("daemon error"), errors.New("exit status 1")
+		}
+		return health(ctx, command)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := provider.StartWatchdog(ctx, WatchdogPolicy{Interval: time.Millisecond})
+	require.NoError(t, err)
+
+	event := nextWatchdogEvent(t, events)
+
+	assert.Equal(t, WatchdogRestartFailed, event.Action)
+	assert.Zero(t, event.Restarts)
+	assert.Error(t, event.Err)
+}
+
+func TestWatchdogStopsOnCancel(t *testing.T) {
+	provider, _ := newTestProvider(t, testConfig(), healthHandler(map[string]string{"app": "healthy", "db": "healthy"}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events, err := provider.StartWatchdog(ctx, WatchdogPolicy{Interval: time.Millisecond})
+	require.NoError(t, err)
+
+	cancel()
+	select {
+	case _, ok := <-events:
+		assert.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("watchdog channel was not closed after cancellation")
+	}
+}
+
+func TestWatchdogNotInitialized(t *testing.T) {
+	_, err := NewDockerComposeProvider().StartWatchdog(context.Background(), WatchdogPolicy{})
+	assert.Error(t, err)
+}
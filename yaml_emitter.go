@@ -0,0 +1,245 @@
+package thirdpartyhosting
+
+import (
+	"strconv"
+	"strings"
+)
+
+// yamlKind identifies the shape of a yamlNode
+type yamlKind int
+
+const (
+	yamlScalar yamlKind = iota
+	yamlMapping
+	yamlSequence
+	yamlFlowSequence
+	yamlLiteral
+	yamlAlias // reference to an anchored node; value holds the anchor name
+)
+
+// yamlNode is a minimal YAML document tree. It only covers what compose files need,
+// and keeps mapping keys in insertion order so output is stable.
+type yamlNode struct {
+	kind   yamlKind
+	value  string // scalar text
+	quoted bool   // render the scalar double-quoted
+	keys   []string
+	values []*yamlNode
+	items  []*yamlNode
+	anchor string // anchor name written before a block mapping or sequence, e.g. "&web-environment"
+}
+
+// yamlPlain returns an unquoted scalar node
+func yamlPlain(value string) *yamlNode {
+	return &yamlNode{kind: yamlScalar, value: value}
+}
+
+// yamlQuoted returns a double-quoted scalar node
+func yamlQuoted(value string) *yamlNode {
+	return &yamlNode{kind: yamlScalar, value: value, quoted: true}
+}
+
+// yamlBlock returns a literal block scalar node, rendered with "|" so multi-line text is kept verbatim
+func yamlBlock(value string) *yamlNode {
+	return &yamlNode{kind: yamlLiteral, value: value}
+}
+
+// yamlMap returns an empty mapping node
+func yamlMap() *yamlNode {
+	return &yamlNode{kind: yamlMapping}
+}
+
+// yamlList returns a block sequence of plain scalars
+func yamlList(values []string) *yamlNode {
+	n := &yamlNode{kind: yamlSequence}
+	for _, value := range values {
+		n.items = append(n.items, yamlPlain(value))
+	}
+	return n
+}
+
+// yamlFlowList returns a flow sequence of quoted scalars, e.g. ["CMD", "true"]
+func yamlFlowList(values []string) *yamlNode {
+	n := &yamlNode{kind: yamlFlowSequence}
+	for _, value := range values {
+		n.items = append(n.items, yamlQuoted(value))
+	}
+	return n
+}
+
+// set appends key to a mapping node and returns the node for chaining
+func (n *yamlNode) set(key string, value *yamlNode) *yamlNode {
+	n.keys = append(n.keys, key)
+	n.values = append(n.values, value)
+	return n
+}
+
+// append adds an item to a sequence node
+func (n *yamlNode) append(item *yamlNode) {
+	n.items = append(n.items, item)
+}
+
+// yamlEmitter renders yamlNode trees as block-style YAML
+type yamlEmitter struct {
+	sb           strings.Builder
+	indent       int
+	singleQuotes bool
+}
+
+// marshalYAML renders a top-level mapping with a blank line between its sections
+func marshalYAML(doc *yamlNode, opts RenderOptions) string {
+	e := &yamlEmitter{indent: opts.Indent, singleQuotes: opts.QuoteStyle == "single"}
+	if e.indent <= 0 {
+		e.indent = 2
+	}
+	for i, key := range doc.keys {
+		if i > 0 {
+			e.sb.WriteString("\n")
+		}
+		e.entry(key+":", doc.values[i], 0)
+	}
+	return e.sb.String()
+}
+
+// mapping writes the entries of n at column col. When first is set it replaces the
+// indentation of the first entry, which is how mappings nested in sequences start.
+func (e *yamlEmitter) mapping(n *yamlNode, col int, first string) {
+	for i, key := range n.keys {
+		prefix := strings.Repeat(" ", col)
+		if i == 0 && first != "" {
+			prefix = first
+		}
+		e.entry(prefix+key+":", n.values[i], col)
+	}
+}
+
+// entry writes a "key:" head followed by its value, nesting block values below col
+func (e *yamlEmitter) entry(head string, value *yamlNode, col int) {
+	switch value.kind {
+	case yamlMapping:
+		if len(value.keys) == 0 {
+			e.line(head + " {}")
+			return
+		}
+		e.line(e.anchored(head, value))
+		e.mapping(value, col+e.indent, "")
+	case yamlSequence:
+		if len(value.items) == 0 {
+			e.line(head + " []")
+			return
+		}
+		e.line(e.anchored(head, value))
+		e.sequence(value, col+e.indent)
+	case yamlLiteral:
+		e.literal(head, value.value, col+e.indent)
+	case yamlAlias:
+		e.line(head + " *" + value.value)
+	default:
+		e.line(head + " " + e.inline(value))
+	}
+}
+
+// anchored appends the anchor of a block node to its head, if it has one
+func (e *yamlEmitter) anchored(head string, value *yamlNode) string {
+	if value.anchor == "" {
+		return head
+	}
+	return head + " &" + value.anchor
+}
+
+// sequence writes the items of n as "- " entries at column col
+func (e *yamlEmitter) sequence(n *yamlNode, col int) {
+	dash := strings.Repeat(" ", col) + "- "
+	for _, item := range n.items {
+		if item.kind == yamlMapping && len(item.keys) > 0 {
+			e.mapping(item, col+2, dash)
+			continue
+		}
+		e.line(dash + e.inline(item))
+	}
+}
+
+// literal writes text as a "|" block scalar indented at column col. Text ending in more
+// than one newline is written double-quoted instead, since keeping those newlines in a
+// block scalar would also swallow the blank lines that follow it. An indentation
+// indicator is added when the first line starts with a space.
+func (e *yamlEmitter) literal(head, text string, col int) {
+	trimmed := strings.TrimRight(text, "\n")
+	if len(text)-len(trimmed) > 1 {
+		e.line(head + " " + e.quote(text))
+		return
+	}
+
+	indicator := "|"
+	if strings.HasPrefix(trimmed, " ") {
+		indicator += strconv.Itoa(e.indent)
+	}
+	if trimmed == text {
+		indicator += "-"
+	}
+
+	e.line(head + " " + indicator)
+	prefix := strings.Repeat(" ", col)
+	for _, line := range strings.Split(trimmed, "\n") {
+		if line == "" {
+			e.line("")
+			continue
+		}
+		e.line(prefix + line)
+	}
+}
+
+// inline renders a scalar, flow sequence or empty collection on a single line
+func (e *yamlEmitter) inline(n *yamlNode) string {
+	switch n.kind {
+	case yamlFlowSequence:
+		parts := make([]string, len(n.items))
+		for i, item := range n.items {
+			parts[i] = e.inline(item)
+		}
+		return "[" + strings.Join(parts, ", ") + "]"
+	case yamlMapping:
+		return "{}"
+	case yamlSequence:
+		return "[]"
+	}
+
+	if n.quoted || !plainSafe(n.value) {
+		return e.quote(n.value)
+	}
+	return n.value
+}
+
+// plainSafe reports whether value reads back unchanged as a plain (unquoted) block scalar.
+// Values such as environment entries with surrounding spaces, ": " or " #" inside, or a
+// leading YAML indicator are quoted instead, so user data can't change the document's shape.
+func plainSafe(value string) bool {
+	if value == "" || strings.TrimSpace(value) != value {
+		return false
+	}
+	if strings.IndexFunc(value, func(r rune) bool { return r < ' ' || r == 0x7f }) >= 0 {
+		return false
+	}
+	if strings.ContainsAny(value[:1], "[]{},#&*!|>'\"%@`") {
+		return false
+	}
+	if strings.ContainsAny(value[:1], "-?:") && (len(value) == 1 || value[1] == ' ') {
+		return false
+	}
+	return !strings.Contains(value, ": ") && !strings.Contains(value, " #") && !strings.HasSuffix(value, ":")
+}
+
+// quote renders value as a quoted scalar in the configured style. Single quotes can't
+// express escapes, so values with control characters are double-quoted regardless.
+func (e *yamlEmitter) quote(value string) string {
+	if !e.singleQuotes || strings.IndexFunc(value, func(r rune) bool { return r < ' ' || r == 0x7f }) >= 0 {
+		return strconv.Quote(value)
+	}
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// line writes a single line of output
+func (e *yamlEmitter) line(text string) {
+	e.sb.WriteString(text)
+	e.sb.WriteString("\n")
+}